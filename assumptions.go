@@ -0,0 +1,99 @@
+package propagator
+
+import (
+	"github.com/RoelofRuis/ds"
+	"math/rand"
+)
+
+// SolveWithAssumptions runs the solving algorithm on the Model with the given assumptions applied. Assumptions are
+// Mutations such as those created by Domain.Assign or Variable.ExcludeByValue.
+// Found solutions are reported through the SolutionFound event. Afterwards all changes, including the assumptions,
+// are reverted so the model is left as it was.
+// If no solution could be found, it also returns a minimal set of positions in assumptions that together make the
+// problem unsolvable. This set is empty if the problem is unsolvable regardless of the assumptions.
+func (s *Solver) SolveWithAssumptions(model Model, assumptions ...Mutation) (bool, []int) {
+	mark := len(s.trail)
+	s.assume(assumptions...)
+	solved := s.Solve(model)
+	s.revertTo(mark)
+
+	if solved {
+		return true, nil
+	}
+
+	quiet := s.silent()
+	conflict := minimalSubset(len(assumptions), func(subset []int) bool {
+		selected := make([]Mutation, len(subset))
+		for i, position := range subset {
+			selected[i] = assumptions[position]
+		}
+		return !quiet.isSolvable(model, selected...)
+	})
+
+	return false, conflict
+}
+
+// isSolvable returns whether the model can be solved with the given assumptions, leaving the model unchanged.
+func (s *Solver) isSolvable(model Model, assumptions ...Mutation) bool {
+	mark := len(s.trail)
+	s.assume(assumptions...)
+	solved := s.Solve(model)
+	s.revertTo(mark)
+	return solved
+}
+
+// assume applies the assumptions as a new Mutator on the trail.
+func (s *Solver) assume(assumptions ...Mutation) {
+	mutator := s.newMutator()
+	mutator.Add(assumptions...)
+	mutator.apply()
+}
+
+// silent returns a copy of the solver that searches for a single solution without publishing events. It checks scopes,
+// recovers panics and profiles like the solver, but has its own randomness derived from the seed of the solver, so
+// searching with it does not change the random choices the solver makes afterwards.
+func (s *Solver) silent() Solver {
+	evaluator := newEvaluator()
+	evaluator.strictScopes = s.evaluator.strictScopes
+	evaluator.recoverPanics = s.evaluator.recoverPanics
+	evaluator.profiler = s.evaluator.profiler
+
+	quiet := Solver{
+		seed:         s.seed,
+		rnd:          rand.New(rand.NewSource(s.seed)),
+		domainPicker: s.domainPicker,
+		indexPicker:  s.indexPicker,
+		maxSolutions: 1,
+		events:       ds.NewEventBus[Event](),
+		evaluator:    evaluator,
+		trail:        []*Mutator{},
+		free:         []*Mutator{},
+	}
+	if profiler := evaluator.profiler; profiler != nil {
+		quiet.events.Subscribe(Start, func(e Event) { profiler.initOnce(e.Model) })
+	}
+	return quiet
+}
+
+// minimalSubset shrinks the positions 0 to n-1 to a subset for which isUnsolvable still holds, but that no longer
+// holds when any single position is removed. It uses deletion: each position is dropped in turn and only added back
+// when the remainder becomes solvable.
+func minimalSubset(n int, isUnsolvable func(subset []int) bool) []int {
+	subset := make([]int, n)
+	for i := range subset {
+		subset[i] = i
+	}
+
+	candidate := make([]int, 0, n)
+	for i := 0; i < len(subset); {
+		candidate = append(candidate[:0], subset[:i]...)
+		candidate = append(candidate, subset[i+1:]...)
+		if isUnsolvable(candidate) {
+			subset = append(subset[:0], candidate...)
+			continue
+		}
+		i++
+	}
+
+	return subset
+}
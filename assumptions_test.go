@@ -0,0 +1,107 @@
+package propagator
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSolver_SolveWithAssumptions(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+
+	csp.AddConstraint(largerThan{varA, varB})
+
+	model := csp.Model()
+
+	var solution [2]int
+
+	solver := NewSolver(
+		WithSeed(0),
+		On(SolutionFound, func(m Model) {
+			solution = [2]int{varA.GetAssignedValue(), varB.GetAssignedValue()}
+		}),
+	)
+
+	solved, conflict := solver.SolveWithAssumptions(model, varA.AssignByValue(2))
+
+	if !solved || conflict != nil {
+		t.Fatalf("expected a solution")
+	}
+	if solution != [2]int{2, 1} {
+		t.Fatalf("wrong solution: %v", solution)
+	}
+	if len(varA.AvailableValues()) != 3 || len(varB.AvailableValues()) != 3 {
+		t.Fatalf("model should be reverted after solving")
+	}
+}
+
+func TestSolver_SolveWithAssumptions_Conflict(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+	varC := AddVariableFromValues(csp, "C", []int{1, 2, 3})
+
+	csp.AddConstraint(largerThan{varA, varB})
+
+	model := csp.Model()
+
+	solver := NewSolver(WithSeed(0))
+
+	solved, conflict := solver.SolveWithAssumptions(
+		model,
+		varC.ExcludeByValue(1),
+		varA.AssignByValue(2),
+		varB.AssignByValue(2),
+	)
+
+	if solved {
+		t.Fatalf("expected no solution")
+	}
+	if !slices.Equal(conflict, []int{1, 2}) {
+		t.Fatalf("wrong conflicting assumptions: %v", conflict)
+	}
+	if len(varA.AvailableValues()) != 3 || len(varB.AvailableValues()) != 3 || len(varC.AvailableValues()) != 3 {
+		t.Fatalf("model should be reverted after solving")
+	}
+}
+
+func TestSolver_SolveWithAssumptions_KeepsRandomness(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+	varC := AddVariableFromValues(csp, "C", []int{1, 2, 3})
+
+	csp.AddConstraint(largerThan{varA, varB})
+
+	model := csp.Model()
+
+	assumptions := []Mutation{varC.ExcludeByValue(1), varA.AssignByValue(2), varB.AssignByValue(2)}
+
+	solver := NewSolver(WithSeed(0))
+	solver.SolveWithAssumptions(model, assumptions...)
+
+	reference := NewSolver(WithSeed(0))
+	reference.isSolvable(model, assumptions...)
+
+	if solver.rnd.Int63() != reference.rnd.Int63() {
+		t.Fatalf("searching for conflicting assumptions should not use the random numbers of the solver")
+	}
+}
+
+func TestSolver_Silent(t *testing.T) {
+	profiler := NewProfiler(context.Background())
+	solver := NewSolver(WithSeed(0), StrictScopes(), ProfileConstraints(profiler))
+	solver.evaluator.recoverPanics = true
+
+	quiet := solver.silent()
+
+	if !quiet.evaluator.strictScopes || !quiet.evaluator.recoverPanics || quiet.evaluator.profiler != profiler {
+		t.Fatalf("expected the evaluator settings to be copied")
+	}
+	if quiet.rnd == solver.rnd || quiet.rnd.Int63() != rand.New(rand.NewSource(0)).Int63() {
+		t.Fatalf("expected randomness derived from the seed")
+	}
+}
@@ -0,0 +1,70 @@
+package propagator
+
+import "testing"
+
+func BenchmarkMutator_ExcludeAndRevert(b *testing.B) {
+	csp := NewProblem()
+	variables := make([]*Variable[int], 0, 64)
+	for i := 0; i < 64; i++ {
+		variables = append(variables, AddVariableFromValues(csp, "", []int{1, 2, 3, 4, 5, 6, 7, 8, 9}))
+	}
+
+	csp.Model()
+
+	mutator := newMutator()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, variable := range variables {
+			mutator.Add(variable.ExcludeByValue(i%9 + 1))
+		}
+		mutator.apply()
+		mutator.revertAll()
+	}
+}
+
+func BenchmarkMutator_UpdateAndRevert(b *testing.B) {
+	csp := NewProblem()
+	variables := make([]*Variable[int], 0, 64)
+	for i := 0; i < 64; i++ {
+		variables = append(variables, AddVariableFromValues(csp, "", []int{1, 2, 3, 4, 5, 6, 7, 8, 9}))
+	}
+
+	model := csp.Model()
+
+	mutator := newMutator()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for c, variable := range variables {
+			mutator.setActiveConstraint(model, c%4, false)
+			mutator.Add(variable.Update(0.5, 1, i%9, (i+1)%9))
+		}
+		mutator.apply()
+		mutator.revertAll()
+	}
+}
+
+func BenchmarkSolver_AllDifferent(b *testing.B) {
+	csp := NewProblem()
+	values := []int{1, 2, 3, 4, 5, 6}
+	variables := make([]*Variable[int], 0, len(values))
+	for range values {
+		variables = append(variables, AddVariableFromValues(csp, "", values))
+	}
+	csp.AddConstraint(distinct{variables, NewReversibleSparseSet(len(values))})
+
+	model := csp.Model()
+
+	solver := NewSolver(WithSeed(0), FindAllSolutions())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		solver.Solve(model)
+		// Revert the found solution, so every iteration solves the same model.
+		solver.revertTo(0)
+	}
+}
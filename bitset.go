@@ -0,0 +1,61 @@
+package propagator
+
+import "math/bits"
+
+// bitset is a fixed size set of indices, stored as one bit per index.
+type bitset []uint64
+
+// newBitset creates an empty bitset that can hold the indices 0 to n-1.
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b bitset) clear(i int) {
+	b[i/64] &^= 1 << (i % 64)
+}
+
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+// countAndNot returns the number of indices in the set that are not in other, which must be of the same size.
+func (b bitset) countAndNot(other bitset) int {
+	count := 0
+	for i, word := range b {
+		count += bits.OnesCount64(word &^ other[i])
+	}
+	return count
+}
+
+// next returns the lowest index in the set that is at least i, or -1 if there is none.
+func (b bitset) next(i int) int {
+	w := i / 64
+	if w >= len(b) {
+		return -1
+	}
+	word := b[w] >> (i % 64)
+	if word != 0 {
+		return i + bits.TrailingZeros64(word)
+	}
+	for w++; w < len(b); w++ {
+		if b[w] != 0 {
+			return w*64 + bits.TrailingZeros64(b[w])
+		}
+	}
+	return -1
+}
+
+// appendTo appends the indices in the set to dst in increasing order and returns the extended slice.
+func (b bitset) appendTo(dst []int) []int {
+	for w, word := range b {
+		for word != 0 {
+			dst = append(dst, w*64+bits.TrailingZeros64(word))
+			word &= word - 1
+		}
+	}
+	return dst
+}
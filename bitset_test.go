@@ -0,0 +1,65 @@
+package propagator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBitset(t *testing.T) {
+	set := newBitset(130)
+	other := newBitset(130)
+
+	for _, i := range []int{0, 5, 63, 64, 129} {
+		set.set(i)
+	}
+	set.clear(5)
+	other.set(64)
+
+	if indices := set.appendTo(nil); !slices.Equal(indices, []int{0, 63, 64, 129}) {
+		t.Errorf("wrong indices: %v", indices)
+	}
+	if count := set.countAndNot(other); count != 3 {
+		t.Errorf("wrong count: %d", count)
+	}
+
+	var iterated []int
+	for i := set.next(0); i != -1; i = set.next(i + 1) {
+		iterated = append(iterated, i)
+	}
+	if !slices.Equal(iterated, []int{0, 63, 64, 129}) {
+		t.Errorf("wrong iteration: %v", iterated)
+	}
+	if next := set.next(130); next != -1 {
+		t.Errorf("expected no next index, got %d", next)
+	}
+}
+
+func TestDomain_Update(t *testing.T) {
+	csp := NewProblem()
+	variable := AddVariable(csp, "A", []DomainValue[int]{
+		{0, 1.0, 1},
+		{0, 0.5, 2},
+		{1, 2.0, 3},
+		{0, 1.0, 4},
+	})
+
+	csp.Model()
+
+	if variable.minPriority() != 0 || variable.sumProbability() != 2.5 {
+		t.Fatalf("wrong initial state: %d %f", variable.minPriority(), variable.sumProbability())
+	}
+
+	mutator := newMutator()
+	mutator.Add(variable.Exclude(0, 1, 3))
+	mutator.apply()
+
+	if variable.minPriority() != 1 || variable.sumProbability() != 2.0 || !slices.Equal(variable.AvailableIndices(), []int{2}) {
+		t.Fatalf("wrong state after exclusion: %d %f %v", variable.minPriority(), variable.sumProbability(), variable.AvailableIndices())
+	}
+
+	mutator.revertAll()
+
+	if variable.minPriority() != 0 || variable.sumProbability() != 2.5 || len(variable.AvailableIndices()) != 4 {
+		t.Fatalf("wrong state after revert: %d %f %v", variable.minPriority(), variable.sumProbability(), variable.AvailableIndices())
+	}
+}
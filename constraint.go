@@ -1,5 +1,10 @@
 package propagator
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Constraint describes the way Domains depend on each other and allows for propagating updated values.
 type Constraint interface {
 	// Scope returns all Domains that are influenced by this constraint.
@@ -8,8 +13,115 @@ type Constraint interface {
 	Propagate(m *Mutator)
 }
 
+// PropagationCost is the cost class of propagating a constraint. Cheaper constraints are propagated first.
+type PropagationCost int
+
+const (
+	// CostUnary is for constraints on a single domain.
+	CostUnary PropagationCost = iota
+	// CostBinary is for constraints between two domains.
+	CostBinary
+	// CostLinear is for constraints whose propagation time is linear in the size of their scope.
+	CostLinear
+	// CostQuadratic is for constraints whose propagation time is quadratic in the size of their scope.
+	CostQuadratic
+	// CostExpensive is for constraints that should only be propagated when nothing cheaper is left.
+	CostExpensive
+
+	numPropagationCosts
+)
+
+// CostedConstraint can be implemented by a Constraint to declare its PropagationCost.
+// Constraints that do not implement it are CostUnary or CostBinary when they have one or two domains in scope, and
+// CostLinear otherwise.
+type CostedConstraint interface {
+	Constraint
+	// Cost returns the cost class of propagating this constraint.
+	Cost() PropagationCost
+}
+
+// Wake is a set of domain events on which a constraint is propagated.
+type Wake uint8
+
+const (
+	// WakeOnAssign is the event of a domain becoming assigned.
+	WakeOnAssign Wake = 1 << iota
+	// WakeOnBounds is the event of the lowest or highest available index of a domain changing.
+	WakeOnBounds
+	// WakeOnDomain is the event of any index being removed from a domain.
+	WakeOnDomain
+	// WakeOnWeights is the event of the probability or priority of an available index changing.
+	WakeOnWeights
+
+	// WakeOnAny combines all events.
+	WakeOnAny = WakeOnAssign | WakeOnBounds | WakeOnDomain | WakeOnWeights
+)
+
+// WatchingConstraint can be implemented by a Constraint to only be propagated on specific events of the domains in its
+// scope. Constraints that do not implement it are propagated on any change.
+// All constraints are propagated once when propagation starts, regardless of the events they watch.
+type WatchingConstraint interface {
+	Constraint
+	// Watches returns the events to be propagated on for every domain in the constraint scope, in the order of Scope.
+	Watches() []Wake
+}
+
+// Checker can be implemented by a Constraint to verify an assignment, independently of its propagation logic. It is
+// used by VerifySolutions to catch propagators that let invalid assignments through.
+type Checker interface {
+	// Check returns whether the constraint holds. It is only called when all domains in the scope are assigned.
+	Check() bool
+}
+
+// IncrementalConstraint can be implemented by a Constraint to be told what changed since it was last propagated.
+// PropagateDelta is then called instead of Propagate.
+type IncrementalConstraint interface {
+	Constraint
+	// PropagateDelta propagates the changes in delta. The delta is only valid during the call.
+	PropagateDelta(m *Mutator, delta Delta)
+}
+
+// Delta describes the changes to the domains in the scope of a constraint since it was last propagated.
+type Delta struct {
+	// Full is set when the changes are not known, which is the case the first time the constraint is propagated and
+	// after the solver reverted changes. The constraint should then consider its whole scope.
+	Full bool
+	// Changes holds the changed domains in the order in which they were first changed.
+	Changes []DomainDelta
+}
+
+// DomainDelta describes the changes to a single domain.
+type DomainDelta struct {
+	Domain DomainId
+	// Removed holds the indices that were removed from the domain. It is empty if only probabilities or priorities
+	// were changed.
+	Removed []int
+}
+
 type constraintId = int
 
+// ConstraintInfo describes a constraint that was added to a Problem.
+type ConstraintInfo struct {
+	// Id is the position of the constraint in the order in which constraints were added.
+	Id int
+	// Type is the name of the Go type implementing the constraint.
+	Type string
+	// Domains holds the names of the domains in the constraint scope.
+	Domains []string
+	// Reason is the reason the constraint gave for failing, if any. It is only set in explanations.
+	Reason string
+}
+
+// String formats the constraint info on two lines, the second listing the linked domain names. If there is a reason,
+// it is added on a third line.
+func (c ConstraintInfo) String() string {
+	info := fmt.Sprintf("%-4d %s\n     %s", c.Id, c.Type, strings.Join(c.Domains, " "))
+	if c.Reason != "" {
+		info += "\n     " + c.Reason
+	}
+	return info
+}
+
 // IdsOf extracts the DomainId from a list of variables.
 func IdsOf[T comparable](vars ...*Variable[T]) []DomainId {
 	domainIds := make([]DomainId, 0, len(vars))
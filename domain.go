@@ -13,7 +13,7 @@ type Domain struct {
 
 // Assign creates a Mutation that assigns the given index to this domain.
 func (d *Domain) Assign(index int) Mutation {
-	if index >= len(d.model.domainIndices[d.id]) {
+	if index >= d.numIndices() {
 		return d.Contradict()
 	}
 
@@ -58,12 +58,9 @@ func (d *Domain) Update(probabilityFactor float64, priority int, indices ...int)
 		return DoNothing
 	}
 
-	mutationIndices := make([]int, len(indices))
-	copy(mutationIndices, indices)
-
 	return Mutation{
 		domain:      d,
-		indices:     mutationIndices,
+		indices:     d.model.copyIndices(indices),
 		probability: probabilityFactor,
 		priority:    priority,
 	}
@@ -84,14 +81,20 @@ func (d *Domain) IsInContradiction() bool {
 	return len(d.AvailableIndices()) == 0
 }
 
-// IndexPriority returns the priority of the given index.
+// IndexPriority returns the priority of the given index, which is math.MaxInt if the index is banned.
 func (d *Domain) IndexPriority(index int) int {
-	return d.indices()[index].priority
+	if !d.isAvailable(index) {
+		return math.MaxInt
+	}
+	return d.weights()[index].priority
 }
 
-// IndexProbability returns the probability of the given index.
+// IndexProbability returns the probability of the given index, which is 0 if the index is banned.
 func (d *Domain) IndexProbability(index int) float64 {
-	return d.indices()[index].probability
+	if !d.isAvailable(index) {
+		return 0.0
+	}
+	return d.weights()[index].probability
 }
 
 // Name returns the name of this domain.
@@ -116,6 +119,11 @@ func (d *Domain) CanBePicked() bool {
 	return d.IsUnassigned() && !d.IsHidden()
 }
 
+// indexValue returns the value of the given index.
+func (d *Domain) indexValue(index int) any {
+	return d.model.domainValues[d.id](index)
+}
+
 func (d *Domain) numIndices() int {
 	return d.model.domainNumIndices[d.id]
 }
@@ -124,12 +132,28 @@ func (d *Domain) version() int {
 	return d.model.domainVersions[d.id]
 }
 
-func (d *Domain) getIndex(i int) *index {
-	return d.model.domainIndices[d.id][i]
+func (d *Domain) weights() []weight {
+	return d.model.domainWeights[d.id]
 }
 
-func (d *Domain) setIndex(i int, idx *index) {
-	d.model.domainIndices[d.id][i] = idx
+func (d *Domain) isAvailable(i int) bool {
+	return d.model.domainAvailable[d.id].has(i)
+}
+
+// setIndex sets the weight of index i and whether it is available. Call update afterwards.
+func (d *Domain) setIndex(i int, w weight, available bool) {
+	d.model.domainWeights[d.id][i] = w
+
+	if available {
+		d.model.domainAvailable[d.id].set(i)
+	} else {
+		d.model.domainAvailable[d.id].clear(i)
+	}
+	if available && w != defaultWeight {
+		d.model.domainWeighted[d.id].set(i)
+	} else {
+		d.model.domainWeighted[d.id].clear(i)
+	}
 }
 
 func (d *Domain) sumProbability() float64 {
@@ -140,16 +164,15 @@ func (d *Domain) minPriority() int {
 	return d.model.domainMinPriority[d.id]
 }
 
-func (d *Domain) indices() []*index {
-	return d.model.domainIndices[d.id]
-}
-
 // numRelevantConstraints returns the number of constraints that this domain shares with other domains that are still
 // unassigned.
 func (d *Domain) numRelevantConstraints() int {
 	count := 0
 iterateConstraints:
 	for _, constraintId := range d.model.domainConstraints[d.id] {
+		if d.model.constraintDisabled[constraintId] {
+			continue
+		}
 		constraint := d.model.constraints[constraintId]
 		for _, linkedId := range constraint.linkedDomains {
 			if linkedId == d.id {
@@ -175,11 +198,12 @@ func (d *Domain) entropy() float64 {
 	}
 
 	entropy := 0.0
-	for _, idx := range d.indices() {
-		if idx.isBanned || idx.priority != d.minPriority() {
+	weights := d.weights()
+	for _, i := range d.AvailableIndices() {
+		if weights[i].priority != d.minPriority() {
 			continue
 		}
-		weightedProb := idx.probability / d.sumProbability()
+		weightedProb := weights[i].probability / d.sumProbability()
 		entropy += weightedProb * math.Log2(weightedProb)
 	}
 	d.model.domainEntropy[d.id] = -entropy
@@ -188,25 +212,35 @@ func (d *Domain) entropy() float64 {
 
 // update is called internally after applying a mutation.
 // It resets internal domain state and precalculate values.
+// Available indices with default probability and priority are only counted; only the weighted indices are visited.
 func (d *Domain) update() {
+	available := d.model.domainAvailable[d.id]
+	weighted := d.model.domainWeighted[d.id]
+	weights := d.weights()
+
 	d.model.domainVersions[d.id]++
-	d.model.domainSumProbability[d.id] = 0.0
-	d.model.domainMinPriority[d.id] = math.MaxInt
 	d.model.domainEntropy[d.id] = math.Inf(+1)
-	d.model.domainAvailableIndices[d.id] = d.model.domainAvailableIndices[d.id][:0]
+	d.model.domainAvailableIndices[d.id] = available.appendTo(d.model.domainAvailableIndices[d.id][:0])
 
-	for i, idx := range d.model.domainIndices[d.id] {
-		if !idx.isBanned {
-			d.model.domainAvailableIndices[d.id] = append(d.model.domainAvailableIndices[d.id], i)
-		}
-		if !idx.isBanned && idx.priority < d.model.domainMinPriority[d.id] {
-			d.model.domainMinPriority[d.id] = idx.priority
-		}
+	numDefault := available.countAndNot(weighted)
+	minPriority := math.MaxInt
+	if numDefault > 0 {
+		minPriority = 0
+	}
+	for i := weighted.next(0); i != -1; i = weighted.next(i + 1) {
+		minPriority = min(minPriority, weights[i].priority)
 	}
 
-	for _, idx := range d.model.domainIndices[d.id] {
-		if !idx.isBanned && idx.priority == d.model.domainMinPriority[d.id] {
-			d.model.domainSumProbability[d.id] += idx.probability
+	sumProbability := 0.0
+	if minPriority == 0 {
+		sumProbability = float64(numDefault)
+	}
+	for i := weighted.next(0); i != -1; i = weighted.next(i + 1) {
+		if weights[i].priority == minPriority {
+			sumProbability += weights[i].probability
 		}
 	}
+
+	d.model.domainMinPriority[d.id] = minPriority
+	d.model.domainSumProbability[d.id] = sumProbability
 }
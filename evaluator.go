@@ -0,0 +1,285 @@
+package propagator
+
+// evaluator propagates the constraints in its queue.
+type evaluator struct {
+	queue    *constraintQueue
+	deltas   []constraintDelta
+	profiler *Profiler
+	// strictScopes makes the mutator reject mutations outside the scope of the propagated constraint.
+	strictScopes bool
+	// recoverPanics turns panics in constraints into a *ConstraintPanicError. It is set while solving with TrySolve.
+	recoverPanics bool
+}
+
+// constraintDelta records the changes for an IncrementalConstraint since it was last propagated.
+type constraintDelta struct {
+	// tracked is whether the changes are known. If not, the constraint receives a full Delta.
+	tracked bool
+	changes []DomainDelta
+}
+
+func newEvaluator() *evaluator {
+	return &evaluator{
+		queue:    newConstraintQueue(),
+		deltas:   []constraintDelta{},
+		profiler: nil,
+
+		strictScopes:  false,
+		recoverPanics: false,
+	}
+}
+
+// enqueueDomain queues the propagated constraints that have the given domain in scope, without knowing what changed.
+func (e *evaluator) enqueueDomain(m Model, domain *Domain) {
+	for _, id := range m.domainConstraints[domain.id] {
+		if !m.isPropagated(id) {
+			continue
+		}
+		if m.constraints[id].incremental != nil {
+			e.untrack(id)
+		}
+		e.queue.enqueue(id, m.constraints[id].cost)
+	}
+}
+
+// enqueueApplied queues the propagated constraints that watch the events caused by the mutations of the last apply of
+// the mutator, and records the changes for incremental constraints. Constraints are queued in the order in which the
+// mutations were added, and per domain in the order in which the constraints were added to the problem, which keeps
+// the propagation order deterministic.
+func (e *evaluator) enqueueApplied(m Model, mutator *Mutator) {
+	for _, mutation := range mutator.mutations[mutator.prevHead:mutator.head] {
+		if len(mutation.changes) == 0 {
+			continue
+		}
+
+		domain := mutation.domain
+		for i, id := range m.domainConstraints[domain.id] {
+			if !m.isPropagated(id) {
+				continue
+			}
+			if m.constraints[id].incremental != nil {
+				e.record(id, &mutation)
+			}
+			if m.domainWakes[domain.id][i]&mutation.events != 0 {
+				e.queue.enqueue(id, m.constraints[id].cost)
+			}
+		}
+	}
+}
+
+// enqueueDecision queues the propagated constraints that have the decided domain in scope, and records the changes of
+// the last apply of the mutator holding the decision for incremental constraints.
+func (e *evaluator) enqueueDecision(m Model, domain *Domain, decision *Mutator) {
+	e.enqueueApplied(m, decision)
+	for _, id := range m.domainConstraints[domain.id] {
+		if !m.isPropagated(id) {
+			continue
+		}
+		e.queue.enqueue(id, m.constraints[id].cost)
+	}
+}
+
+// record adds the changes of the mutation to the delta of the constraint, if the delta is tracked.
+func (e *evaluator) record(id constraintId, mutation *Mutation) {
+	delta := e.delta(id)
+	if !delta.tracked {
+		return
+	}
+
+	var change *DomainDelta
+	for i := range delta.changes {
+		if delta.changes[i].Domain == mutation.domain.id {
+			change = &delta.changes[i]
+			break
+		}
+	}
+	if change == nil {
+		// The removed indices of an earlier delta are reused, as a delta is only valid during the call.
+		if len(delta.changes) < cap(delta.changes) {
+			delta.changes = delta.changes[:len(delta.changes)+1]
+		} else {
+			delta.changes = append(delta.changes, DomainDelta{})
+		}
+		change = &delta.changes[len(delta.changes)-1]
+		change.Domain = mutation.domain.id
+		change.Removed = change.Removed[:0]
+	}
+
+	for _, changed := range mutation.changes {
+		if changed.banned {
+			change.Removed = append(change.Removed, changed.index)
+		}
+	}
+}
+
+// delta returns the delta of the constraint, growing the deltas as needed.
+func (e *evaluator) delta(id constraintId) *constraintDelta {
+	for id >= len(e.deltas) {
+		e.deltas = append(e.deltas, constraintDelta{tracked: false, changes: nil})
+	}
+	return &e.deltas[id]
+}
+
+// untrack marks the changes of the constraint as unknown.
+func (e *evaluator) untrack(id constraintId) {
+	delta := e.delta(id)
+	delta.tracked = false
+	delta.changes = delta.changes[:0]
+}
+
+// untrackAll marks the changes of all constraints as unknown. It is called whenever changes are reverted, as the
+// recorded deltas then no longer describe the model.
+func (e *evaluator) untrackAll() {
+	for id := range e.deltas {
+		e.untrack(id)
+	}
+}
+
+// reset empties the queue and forgets the recorded deltas, after propagation was interrupted.
+func (e *evaluator) reset() {
+	e.queue.reset()
+	e.untrackAll()
+}
+
+// TODO: this has been separated for use in LeastConstrainingValueIndexPicker
+func (e *evaluator) evaluate(m Model, mutator *Mutator) bool {
+	for !e.queue.isEmpty() {
+		if _, success := e.evaluateNext(m, mutator); !success {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluateNext propagates the next constraint in the queue and applies its mutations, queueing the constraints of the
+// changed domains that watch the events of the change. It returns the constraint and whether propagation succeeded,
+// meaning it did not lead to a contradiction.
+func (e *evaluator) evaluateNext(m Model, mutator *Mutator) (constraintId, bool) {
+	id, hasNext := e.queue.dequeue()
+	if !hasNext {
+		return -1, true
+	}
+
+	mutator.setActiveConstraint(m, id, e.strictScopes)
+	if e.profiler != nil {
+		e.profiler.propagate(id, func() { e.propagate(m, id, mutator) })
+	} else {
+		e.propagate(m, id, mutator)
+	}
+	mutator.clearActiveConstraint()
+
+	if mutator.failed {
+		mutator.discardPending()
+		return id, e.fail(mutator)
+	}
+
+	head := mutator.head
+	mutator.apply()
+	if e.profiler != nil {
+		e.profiler.applied(mutator, head)
+	}
+
+	if mutator.contradiction != nil {
+		return id, e.fail(mutator)
+	}
+
+	e.enqueueApplied(m, mutator)
+
+	return id, true
+}
+
+// fail stops propagation after a contradiction. It always returns false.
+func (e *evaluator) fail(mutator *Mutator) bool {
+	if e.profiler != nil {
+		e.profiler.contradiction(mutator)
+	}
+	e.queue.reset()
+	return false
+}
+
+// propagate propagates a single constraint, passing the recorded delta to incremental constraints.
+// If panics are recovered, a panic in the constraint is turned into a *ConstraintPanicError.
+func (e *evaluator) propagate(m Model, id constraintId, mutator *Mutator) {
+	if e.recoverPanics {
+		defer recoverConstraintPanic(m, id)
+	}
+
+	incremental := m.constraints[id].incremental
+	if incremental == nil {
+		m.constraints[id].constraint.Propagate(mutator)
+		return
+	}
+
+	delta := e.delta(id)
+	if delta.tracked {
+		incremental.PropagateDelta(mutator, Delta{Full: false, Changes: delta.changes})
+	} else {
+		incremental.PropagateDelta(mutator, Delta{Full: true, Changes: nil})
+	}
+	delta.tracked = true
+	delta.changes = delta.changes[:0]
+}
+
+// constraintQueue queues constraints by their PropagationCost. Constraints are dequeued cheapest cost first, and in
+// the order in which they were queued within the same cost. A constraint is queued at most once.
+type constraintQueue struct {
+	buckets [numPropagationCosts][]constraintId
+	heads   [numPropagationCosts]int
+	queued  []bool
+	size    int
+}
+
+func newConstraintQueue() *constraintQueue {
+	return &constraintQueue{
+		queued: []bool{},
+		size:   0,
+	}
+}
+
+func (q *constraintQueue) enqueue(id constraintId, cost PropagationCost) {
+	for id >= len(q.queued) {
+		q.queued = append(q.queued, false)
+	}
+	if q.queued[id] {
+		return
+	}
+	q.queued[id] = true
+	q.buckets[cost] = append(q.buckets[cost], id)
+	q.size++
+}
+
+func (q *constraintQueue) dequeue() (constraintId, bool) {
+	if q.size == 0 {
+		return -1, false
+	}
+	for cost := range q.buckets {
+		if q.heads[cost] == len(q.buckets[cost]) {
+			continue
+		}
+		id := q.buckets[cost][q.heads[cost]]
+		q.heads[cost]++
+		if q.heads[cost] == len(q.buckets[cost]) {
+			q.buckets[cost] = q.buckets[cost][:0]
+			q.heads[cost] = 0
+		}
+		q.queued[id] = false
+		q.size--
+		return id, true
+	}
+	return -1, false
+}
+
+func (q *constraintQueue) isEmpty() bool {
+	return q.size == 0
+}
+
+func (q *constraintQueue) reset() {
+	for cost := range q.buckets {
+		for _, id := range q.buckets[cost][q.heads[cost]:] {
+			q.queued[id] = false
+		}
+		q.buckets[cost] = q.buckets[cost][:0]
+		q.heads[cost] = 0
+	}
+	q.size = 0
+}
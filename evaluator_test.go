@@ -0,0 +1,338 @@
+package propagator
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestEvaluator_PropagatesCheapestFirst(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2})
+	varC := AddVariableFromValues(csp, "C", []int{1, 2})
+
+	var calls []string
+
+	csp.AddConstraint(costedRecorder{recorder{"expensive", IdsOf(varA), &calls}, CostExpensive})
+	csp.AddConstraint(recorder{"linear", IdsOf(varA, varB, varC), &calls})
+	csp.AddConstraint(recorder{"binary", IdsOf(varA, varB), &calls})
+	csp.AddConstraint(recorder{"unary", IdsOf(varC), &calls})
+
+	model := csp.Model()
+
+	if _, success := Propagate(model); !success {
+		t.Fatalf("expected propagation to succeed")
+	}
+
+	if !slices.Equal(calls, []string{"unary", "binary", "linear", "expensive"}) {
+		t.Fatalf("wrong propagation order: %v", calls)
+	}
+}
+
+func TestEvaluator_PropagatesConstraintOnce(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+	varC := AddVariableFromValues(csp, "C", []int{1, 2, 3})
+
+	var calls []string
+
+	csp.AddConstraint(recorder{"abc", IdsOf(varA, varB, varC), &calls})
+	csp.AddConstraint(largerThan{varA, varB})
+	csp.AddConstraint(largerThan{varB, varC})
+
+	model := csp.Model()
+
+	if _, success := Propagate(model); !success {
+		t.Fatalf("expected propagation to succeed")
+	}
+
+	if len(calls) != 1 {
+		t.Fatalf("constraint should only be propagated after the cheaper constraints are done: %v", calls)
+	}
+}
+
+func TestEvaluator_WakesWatchingConstraints(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+
+	var calls []string
+
+	csp.AddConstraint(recorder{"any", IdsOf(varA), &calls})
+	csp.AddConstraint(watchingRecorder{recorder{"assign", IdsOf(varA), &calls}, WakeOnAssign})
+	csp.AddConstraint(watchingRecorder{recorder{"bounds", IdsOf(varA), &calls}, WakeOnBounds})
+	csp.AddConstraint(watchingRecorder{recorder{"domain", IdsOf(varA), &calls}, WakeOnDomain})
+
+	model := csp.Model()
+
+	evaluator := newEvaluator()
+	mutator := newMutator()
+
+	mutator.Add(varA.Exclude(1))
+	mutator.apply()
+	evaluator.enqueueApplied(model, mutator)
+
+	if !evaluator.evaluate(model, mutator) {
+		t.Fatalf("expected propagation to succeed")
+	}
+
+	if !slices.Equal(calls, []string{"any", "domain"}) {
+		t.Fatalf("wrong constraints woken: %v", calls)
+	}
+}
+
+func TestEvaluator_PassesDelta(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+
+	var deltas []Delta
+
+	csp.AddConstraint(deltaRecorder{IdsOf(varA, varB), &deltas})
+
+	model := csp.Model()
+
+	evaluator := newEvaluator()
+	mutator := newMutator()
+
+	for _, domain := range model.Domains {
+		evaluator.enqueueDomain(model, domain)
+	}
+	if !evaluator.evaluate(model, mutator) {
+		t.Fatalf("expected propagation to succeed")
+	}
+
+	mutator.Add(varA.Exclude(0, 2), varB.UpdateProbability(0.5, 1))
+	mutator.apply()
+	evaluator.enqueueApplied(model, mutator)
+	if !evaluator.evaluate(model, mutator) {
+		t.Fatalf("expected propagation to succeed")
+	}
+
+	if len(deltas) != 2 {
+		t.Fatalf("expected 2 propagations, got %d", len(deltas))
+	}
+	if !deltas[0].Full {
+		t.Errorf("expected full delta on first propagation")
+	}
+	expected := "{false [{0 [0 2]} {1 []}]}"
+	if actual := fmt.Sprintf("%v", deltas[1]); actual != expected {
+		t.Errorf("wrong delta: expected %s, got %s", expected, actual)
+	}
+
+	mutator.revertAll()
+	evaluator.untrackAll()
+	evaluator.enqueueDomain(model, &varA.Domain)
+	if !evaluator.evaluate(model, mutator) {
+		t.Fatalf("expected propagation to succeed")
+	}
+	if !deltas[2].Full {
+		t.Errorf("expected full delta after reverting")
+	}
+}
+
+func TestEvaluator_SkipsEntailedConstraints(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+
+	var calls []string
+
+	csp.AddConstraint(entailingRecorder{recorder{"entailed", IdsOf(varA), &calls}})
+	csp.AddConstraint(recorder{"any", IdsOf(varA), &calls})
+
+	model := csp.Model()
+
+	evaluator := newEvaluator()
+	mutator := newMutator()
+
+	evaluator.enqueueDomain(model, &varA.Domain)
+	if !evaluator.evaluate(model, mutator) {
+		t.Fatalf("expected propagation to succeed")
+	}
+
+	mutator.Add(varA.Exclude(0))
+	mutator.apply()
+	evaluator.enqueueApplied(model, mutator)
+	if !evaluator.evaluate(model, mutator) {
+		t.Fatalf("expected propagation to succeed")
+	}
+
+	if !slices.Equal(calls, []string{"entailed", "any", "any"}) {
+		t.Fatalf("entailed constraint should not be propagated again: %v", calls)
+	}
+
+	mutator.revertAll()
+	evaluator.enqueueDomain(model, &varA.Domain)
+	if !evaluator.evaluate(model, mutator) {
+		t.Fatalf("expected propagation to succeed")
+	}
+
+	if !slices.Equal(calls, []string{"entailed", "any", "any", "entailed", "any"}) {
+		t.Fatalf("entailed constraint should be propagated again after reverting: %v", calls)
+	}
+}
+
+func TestEvaluator_EntailOutsidePropagation(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+
+	var calls []string
+
+	csp.AddConstraint(recorder{"any", IdsOf(varA), &calls})
+
+	model := csp.Model()
+
+	evaluator := newEvaluator()
+	mutator := newMutator()
+
+	evaluator.enqueueDomain(model, &varA.Domain)
+	if !evaluator.evaluate(model, mutator) {
+		t.Fatalf("expected propagation to succeed")
+	}
+
+	mutator.Add(varA.Exclude(0))
+	mutator.Entail()
+	mutator.apply()
+
+	if model.constraintEntailed[0] {
+		t.Fatalf("entailing after propagation should not entail the last propagated constraint")
+	}
+	if mutator.mutations[0].constraintId != -1 {
+		t.Fatalf("mutation added after propagation should not be attributed to a constraint")
+	}
+}
+
+func TestEvaluator_Fail(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+
+	csp.AddConstraint(failing{varA, "always fails"})
+
+	model := csp.Model()
+
+	evaluator := newEvaluator()
+	mutator := newMutator()
+
+	evaluator.enqueueDomain(model, &varA.Domain)
+	if evaluator.evaluate(model, mutator) {
+		t.Fatalf("expected propagation to fail")
+	}
+
+	if mutator.contradictionConstraintId != 0 || mutator.contradictionReason != "always fails" || mutator.contradiction != nil {
+		t.Fatalf("wrong failure: %d %q", mutator.contradictionConstraintId, mutator.contradictionReason)
+	}
+	if len(varA.AvailableIndices()) != 3 {
+		t.Fatalf("mutations of the failing constraint should not be applied")
+	}
+	if !evaluator.queue.isEmpty() {
+		t.Fatalf("queue should be empty after failure")
+	}
+}
+
+func TestEvaluator_FailOutsidePropagation(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+
+	var calls []string
+
+	csp.AddConstraint(recorder{"any", IdsOf(varA), &calls})
+
+	model := csp.Model()
+
+	evaluator := newEvaluator()
+	mutator := newMutator()
+
+	evaluator.enqueueDomain(model, &varA.Domain)
+	if !evaluator.evaluate(model, mutator) {
+		t.Fatalf("expected propagation to succeed")
+	}
+
+	mutator.Fail("too late")
+
+	if mutator.failed || mutator.contradictionConstraintId != -1 || mutator.contradictionReason != "" {
+		t.Fatalf("failing after propagation should not blame the last propagated constraint")
+	}
+}
+
+// recorder is a constraint that records its name when propagated.
+type recorder struct {
+	name  string
+	scope []DomainId
+	calls *[]string
+}
+
+func (r recorder) Scope() []DomainId {
+	return r.scope
+}
+
+func (r recorder) Propagate(m *Mutator) {
+	*r.calls = append(*r.calls, r.name)
+}
+
+// costedRecorder is a recorder with an explicit PropagationCost.
+type costedRecorder struct {
+	recorder
+	cost PropagationCost
+}
+
+func (r costedRecorder) Cost() PropagationCost {
+	return r.cost
+}
+
+// entailingRecorder is a recorder that declares itself entailed when propagated.
+type entailingRecorder struct {
+	recorder
+}
+
+func (r entailingRecorder) Propagate(m *Mutator) {
+	r.recorder.Propagate(m)
+	m.Entail()
+}
+
+// watchingRecorder is a recorder that is only woken on the given events.
+type watchingRecorder struct {
+	recorder
+	wake Wake
+}
+
+func (r watchingRecorder) Watches() []Wake {
+	return []Wake{r.wake}
+}
+
+// deltaRecorder is an incremental constraint that records the deltas it receives.
+type deltaRecorder struct {
+	scope  []DomainId
+	deltas *[]Delta
+}
+
+func (r deltaRecorder) Scope() []DomainId {
+	return r.scope
+}
+
+func (r deltaRecorder) Propagate(m *Mutator) {
+	panic("incremental constraint should not be propagated without delta")
+}
+
+func (r deltaRecorder) PropagateDelta(m *Mutator, delta Delta) {
+	changes := make([]DomainDelta, 0, len(delta.Changes))
+	for _, change := range delta.Changes {
+		changes = append(changes, DomainDelta{change.Domain, slices.Clone(change.Removed)})
+	}
+	*r.deltas = append(*r.deltas, Delta{delta.Full, changes})
+}
+
+// failing is a constraint that excludes an index and then fails.
+type failing struct {
+	v      *Variable[int]
+	reason string
+}
+
+func (f failing) Scope() []DomainId {
+	return IdsOf(f.v)
+}
+
+func (f failing) Propagate(m *Mutator) {
+	m.Add(f.v.Exclude(0))
+	m.Fail(f.reason)
+}
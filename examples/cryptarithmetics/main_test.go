@@ -10,6 +10,7 @@ func BenchmarkSolve(b *testing.B) {
 		propagator.WithSeed(0),
 	)
 
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		Solve(solver)
 	}
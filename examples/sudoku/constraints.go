@@ -12,9 +12,19 @@ func (h House) Scope() []propagator.DomainId {
 	return propagator.IdsOf(h.Cells...)
 }
 
+func (h House) Watches() []propagator.Wake {
+	wakes := make([]propagator.Wake, len(h.Cells))
+	for i := range wakes {
+		wakes[i] = propagator.WakeOnAssign
+	}
+	return wakes
+}
+
 func (h House) Propagate(mutator *propagator.Mutator) {
+	entailed := true
 	for _, i := range h.Cells {
 		if !i.IsAssigned() {
+			entailed = false
 			continue
 		}
 
@@ -25,4 +35,7 @@ func (h House) Propagate(mutator *propagator.Mutator) {
 			mutator.Add(j.ExcludeByValue(i.GetAssignedValue()))
 		}
 	}
+	if entailed {
+		mutator.Entail()
+	}
 }
@@ -21,8 +21,15 @@ func (a AdjacentCells) Scope() []propagator.DomainId {
 	return propagator.IdsOf(a.CellA, a.CellB)
 }
 
+func (a AdjacentCells) Watches() []propagator.Wake {
+	return []propagator.Wake{propagator.WakeOnAssign, propagator.WakeOnAssign}
+}
+
 func (a AdjacentCells) Propagate(mutator *propagator.Mutator) {
-	if a.CellA.IsAssigned() {
+	if a.CellA.IsAssigned() && a.CellB.IsAssigned() {
+		mutator.Add(a.CellB.ExcludeByValue(a.CellA.GetAssignedValue()))
+		mutator.Entail()
+	} else if a.CellA.IsAssigned() {
 		mutator.Add(a.CellB.ExcludeByValue(a.CellA.GetAssignedValue()))
 	} else if a.CellB.IsAssigned() {
 		mutator.Add(a.CellA.ExcludeByValue(a.CellB.GetAssignedValue()))
@@ -38,9 +45,19 @@ func (b Block) Scope() []propagator.DomainId {
 	return propagator.IdsOf(b.Cells...)
 }
 
+func (b Block) Watches() []propagator.Wake {
+	wakes := make([]propagator.Wake, len(b.Cells))
+	for i := range wakes {
+		wakes[i] = propagator.WakeOnAssign
+	}
+	return wakes
+}
+
 func (b Block) Propagate(mutator *propagator.Mutator) {
+	entailed := true
 	for _, i := range b.Cells {
 		if !i.IsAssigned() {
+			entailed = false
 			continue
 		}
 		for _, j := range b.Cells {
@@ -50,6 +67,9 @@ func (b Block) Propagate(mutator *propagator.Mutator) {
 			mutator.Add(j.ExcludeByValue(i.GetAssignedValue()))
 		}
 	}
+	if entailed {
+		mutator.Entail()
+	}
 }
 
 type CellData struct {
@@ -107,7 +127,7 @@ func main() {
 				for i := 1; i <= block.Size; i++ {
 					values = append(values, i)
 				}
-				cell = propagator.AddVariableFromValues(csp, fmt.Sprintf("%d", x), values)
+				cell = propagator.AddVariableFromValues(csp, fmt.Sprintf("%d,%d", x, y), values)
 			}
 			block.Cells = append(block.Cells, cell)
 			blocks[cellData.blockId] = block
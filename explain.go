@@ -0,0 +1,42 @@
+package propagator
+
+// Explain finds out why a model cannot be solved. It returns a minimal set of constraints that together already make
+// the model unsolvable, meaning that leaving out any one of them would make the remaining set solvable.
+// Constraints that failed with a reason through Mutator.Fail have the first such reason set in their ConstraintInfo.
+// It returns nil if the model can be solved. The model is left unchanged.
+// Every step requires a full search, so this can be slow for large models.
+func (s *Solver) Explain(model Model) []ConstraintInfo {
+	quiet := s.silent()
+	reasons := make(map[constraintId]string)
+	quiet.events.Subscribe(Contradiction, func(e Event) {
+		if _, has := reasons[e.ConstraintId]; !has && e.Reason != "" {
+			reasons[e.ConstraintId] = e.Reason
+		}
+	})
+
+	if quiet.isSolvable(model) {
+		return nil
+	}
+
+	disabled := model.constraintDisabled
+	core := minimalSubset(len(model.constraints), func(subset []int) bool {
+		for id := range disabled {
+			disabled[id] = true
+		}
+		for _, id := range subset {
+			disabled[id] = false
+		}
+		solvable := quiet.isSolvable(model)
+		for id := range disabled {
+			disabled[id] = false
+		}
+		return !solvable
+	})
+
+	explanation := make([]ConstraintInfo, len(core))
+	for i, id := range core {
+		explanation[i] = model.describeConstraint(id)
+		explanation[i].Reason = reasons[id]
+	}
+	return explanation
+}
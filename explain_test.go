@@ -0,0 +1,95 @@
+package propagator
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSolver_Explain(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2})
+	varC := AddVariableFromValues(csp, "C", []int{1, 2})
+	varD := AddVariableFromValues(csp, "D", []int{1, 2})
+
+	csp.AddConstraint(equals{varC, varD})
+	csp.AddConstraint(largerThan{varA, varB})
+	csp.AddConstraint(largerThan{varB, varC})
+
+	model := csp.Model()
+
+	solver := NewSolver(WithSeed(0))
+
+	explanation := solver.Explain(model)
+
+	if len(explanation) != 2 {
+		t.Fatalf("wrong explanation: %v", explanation)
+	}
+	if explanation[0].Id != 1 || explanation[0].Type != "propagator.largerThan" || explanation[0].Domains[0] != "A" || explanation[0].Domains[1] != "B" {
+		t.Fatalf("wrong first constraint: %v", explanation[0])
+	}
+	if explanation[1].Id != 2 || explanation[1].Domains[0] != "B" || explanation[1].Domains[1] != "C" {
+		t.Fatalf("wrong second constraint: %v", explanation[1])
+	}
+	if len(varA.AvailableValues()) != 2 || len(varC.AvailableValues()) != 2 {
+		t.Fatalf("model should be left unchanged")
+	}
+}
+
+func TestSolver_Explain_Solvable(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2})
+
+	csp.AddConstraint(largerThan{varA, varB})
+
+	model := csp.Model()
+
+	solver := NewSolver(WithSeed(0))
+
+	if explanation := solver.Explain(model); explanation != nil {
+		t.Fatalf("expected no explanation for solvable model: %v", explanation)
+	}
+}
+
+func TestSolver_Explain_Reason(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2})
+
+	csp.AddConstraint(largerThan{varA, varB})
+	csp.AddConstraint(sumAtMost{varA, varB, 2})
+
+	model := csp.Model()
+
+	solver := NewSolver(WithSeed(0))
+
+	explanation := solver.Explain(model)
+
+	if len(explanation) != 2 {
+		t.Fatalf("wrong explanation: %v", explanation)
+	}
+	if explanation[0].Id != 0 || explanation[0].Reason != "" {
+		t.Fatalf("wrong first constraint: %v", explanation[0])
+	}
+	if explanation[1].Id != 1 || explanation[1].Reason != "sum exceeds 2" {
+		t.Fatalf("wrong second constraint: %v", explanation[1])
+	}
+}
+
+// sumAtMost fails when the assigned values of a and b sum to more than max.
+type sumAtMost struct {
+	a   *Variable[int]
+	b   *Variable[int]
+	max int
+}
+
+func (s sumAtMost) Scope() []DomainId {
+	return IdsOf(s.a, s.b)
+}
+
+func (s sumAtMost) Propagate(m *Mutator) {
+	if s.a.IsAssigned() && s.b.IsAssigned() && s.a.GetAssignedValue()+s.b.GetAssignedValue() > s.max {
+		m.Fail(fmt.Sprintf("sum exceeds %d", s.max))
+	}
+}
@@ -0,0 +1,192 @@
+package propagator
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ConstraintGraphMetrics summarises the constraint graph of a model, in which domains are linked to the constraints
+// that have them in scope.
+type ConstraintGraphMetrics struct {
+	NumDomains     int
+	NumConstraints int
+	// MaxArity is the largest number of domains in the scope of a single constraint.
+	MaxArity int
+	// DegreeDistribution maps a degree, the number of constraints a domain is in scope of, to the number of domains
+	// with that degree.
+	DegreeDistribution map[int]int
+	// Components holds the connected components of the graph as lists of domains. Domains that share no constraint
+	// with any other domain form a component on their own.
+	Components [][]DomainId
+	// DuplicateNames holds the domain names that are used by more than one domain.
+	DuplicateNames []string
+}
+
+// String formats the metrics as a human-readable summary.
+func (c ConstraintGraphMetrics) String() string {
+	degrees := make([]int, 0, len(c.DegreeDistribution))
+	for degree := range c.DegreeDistribution {
+		degrees = append(degrees, degree)
+	}
+	sort.Ints(degrees)
+
+	var distribution []string
+	for _, degree := range degrees {
+		distribution = append(distribution, fmt.Sprintf("%d:%d", degree, c.DegreeDistribution[degree]))
+	}
+
+	return fmt.Sprintf(
+		"domains: %d\nconstraints: %d\nmax arity: %d\ndegrees: %s\ncomponents: %d\nduplicate names: %s",
+		c.NumDomains,
+		c.NumConstraints,
+		c.MaxArity,
+		strings.Join(distribution, " "),
+		len(c.Components),
+		strings.Join(c.DuplicateNames, " "),
+	)
+}
+
+// AnalyzeConstraintGraph calculates summary metrics of the constraint graph, which can help to spot modelling mistakes
+// such as unconnected parts of the problem or domains accidentally sharing a name.
+func (m *Model) AnalyzeConstraintGraph() ConstraintGraphMetrics {
+	metrics := ConstraintGraphMetrics{
+		NumDomains:         len(m.Domains),
+		NumConstraints:     len(m.constraints),
+		MaxArity:           0,
+		DegreeDistribution: make(map[int]int),
+		Components:         [][]DomainId{},
+		DuplicateNames:     []string{},
+	}
+
+	for _, boundConstraint := range m.constraints {
+		metrics.MaxArity = max(metrics.MaxArity, len(boundConstraint.linkedDomains))
+	}
+
+	for id := range m.Domains {
+		metrics.DegreeDistribution[len(m.domainConstraints[id])]++
+	}
+
+	// Union-find over the domains, joining all domains in the scope of the same constraint.
+	roots := make([]DomainId, len(m.Domains))
+	for id := range roots {
+		roots[id] = id
+	}
+	var find func(id DomainId) DomainId
+	find = func(id DomainId) DomainId {
+		if roots[id] != id {
+			roots[id] = find(roots[id])
+		}
+		return roots[id]
+	}
+	for _, boundConstraint := range m.constraints {
+		first := find(boundConstraint.linkedDomains[0])
+		for _, linked := range boundConstraint.linkedDomains[1:] {
+			roots[find(linked)] = first
+		}
+	}
+
+	componentIndex := make(map[DomainId]int)
+	for id := range m.Domains {
+		root := find(id)
+		index, has := componentIndex[root]
+		if !has {
+			index = len(metrics.Components)
+			componentIndex[root] = index
+			metrics.Components = append(metrics.Components, []DomainId{})
+		}
+		metrics.Components[index] = append(metrics.Components[index], id)
+	}
+
+	nameCount := make(map[string]int)
+	for _, name := range m.domainNames {
+		nameCount[name]++
+		if nameCount[name] == 2 {
+			metrics.DuplicateNames = append(metrics.DuplicateNames, name)
+		}
+	}
+
+	return metrics
+}
+
+// WriteConstraintGraphDOT writes the constraint graph to w in Graphviz DOT format. Domains are drawn as ellipses
+// labelled with their name, hidden domains dashed, and constraints as boxes labelled with their type.
+func (m *Model) WriteConstraintGraphDOT(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+
+	fmt.Fprintf(buf, "graph constraints {\n")
+	for id, name := range m.domainNames {
+		style := ""
+		if m.domainHidden[id] {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(buf, "\td%d [label=%s, shape=ellipse%s];\n", id, quoteDOT(name), style)
+	}
+	for id := range m.constraints {
+		fmt.Fprintf(buf, "\tc%d [label=%s, shape=box];\n", id, quoteDOT(m.describeConstraint(id).Type))
+	}
+	for id, boundConstraint := range m.constraints {
+		for _, linked := range boundConstraint.linkedDomains {
+			fmt.Fprintf(buf, "\tc%d -- d%d;\n", id, linked)
+		}
+	}
+	fmt.Fprintf(buf, "}\n")
+
+	return buf.Flush()
+}
+
+// WriteConstraintGraphGraphML writes the constraint graph to w in GraphML format. Every node has a kind, which is
+// either "domain" or "constraint", and a label holding the domain name or constraint type.
+func (m *Model) WriteConstraintGraphGraphML(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+
+	fmt.Fprintf(buf, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(buf, "<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\">\n")
+	fmt.Fprintf(buf, "  <key id=\"kind\" for=\"node\" attr.name=\"kind\" attr.type=\"string\"/>\n")
+	fmt.Fprintf(buf, "  <key id=\"label\" for=\"node\" attr.name=\"label\" attr.type=\"string\"/>\n")
+	fmt.Fprintf(buf, "  <key id=\"hidden\" for=\"node\" attr.name=\"hidden\" attr.type=\"boolean\"/>\n")
+	fmt.Fprintf(buf, "  <graph id=\"constraints\" edgedefault=\"undirected\">\n")
+	for id, name := range m.domainNames {
+		fmt.Fprintf(
+			buf,
+			"    <node id=\"d%d\"><data key=\"kind\">domain</data><data key=\"label\">%s</data><data key=\"hidden\">%t</data></node>\n",
+			id,
+			escapeXML(name),
+			m.domainHidden[id],
+		)
+	}
+	for id := range m.constraints {
+		fmt.Fprintf(
+			buf,
+			"    <node id=\"c%d\"><data key=\"kind\">constraint</data><data key=\"label\">%s</data></node>\n",
+			id,
+			escapeXML(m.describeConstraint(id).Type),
+		)
+	}
+	for id, boundConstraint := range m.constraints {
+		for _, linked := range boundConstraint.linkedDomains {
+			fmt.Fprintf(buf, "    <edge source=\"c%d\" target=\"d%d\"/>\n", id, linked)
+		}
+	}
+	fmt.Fprintf(buf, "  </graph>\n")
+	fmt.Fprintf(buf, "</graphml>\n")
+
+	return buf.Flush()
+}
+
+// quoteDOT returns s as a quoted DOT string. Only double quotes and backslashes are escaped, as DOT does not know the
+// other escapes of Go strings and reads UTF-8 as is.
+func quoteDOT(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func escapeXML(s string) string {
+	var builder strings.Builder
+	_ = xml.EscapeText(&builder, []byte(s))
+	return builder.String()
+}
@@ -0,0 +1,78 @@
+package propagator
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestModel_AnalyzeConstraintGraph(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2})
+	varC := AddVariableFromValues(csp, "C", []int{1, 2})
+	AddVariableFromValues(csp, "A", []int{1, 2})
+
+	csp.AddConstraint(largerThan{varA, varB})
+	csp.AddConstraint(largerThan{varB, varC})
+
+	model := csp.Model()
+
+	metrics := model.AnalyzeConstraintGraph()
+
+	if metrics.NumDomains != 4 || metrics.NumConstraints != 2 || metrics.MaxArity != 2 {
+		t.Fatalf("wrong counts: %+v", metrics)
+	}
+	if metrics.DegreeDistribution[0] != 1 || metrics.DegreeDistribution[1] != 2 || metrics.DegreeDistribution[2] != 1 {
+		t.Fatalf("wrong degree distribution: %v", metrics.DegreeDistribution)
+	}
+	if len(metrics.Components) != 2 || !slices.Equal(metrics.Components[0], []DomainId{0, 1, 2}) || !slices.Equal(metrics.Components[1], []DomainId{3}) {
+		t.Fatalf("wrong components: %v", metrics.Components)
+	}
+	if !slices.Equal(metrics.DuplicateNames, []string{"A"}) {
+		t.Fatalf("wrong duplicate names: %v", metrics.DuplicateNames)
+	}
+}
+
+func TestModel_WriteConstraintGraph(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddHiddenVariableFromValues(csp, "B<1> \"é\"\\", []int{1, 2})
+
+	csp.AddConstraint(largerThan{varA, varB})
+
+	model := csp.Model()
+
+	dot := &bytes.Buffer{}
+	if err := model.WriteConstraintGraphDOT(dot); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expectedDOT := `graph constraints {
+	d0 [label="A", shape=ellipse];
+	d1 [label="B<1> \"é\"\\", shape=ellipse, style=dashed];
+	c0 [label="propagator.largerThan", shape=box];
+	c0 -- d0;
+	c0 -- d1;
+}
+`
+	if dot.String() != expectedDOT {
+		t.Fatalf("wrong DOT output:\n%s", dot.String())
+	}
+
+	graphML := &bytes.Buffer{}
+	if err := model.WriteConstraintGraphGraphML(graphML); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, expected := range []string{
+		`<node id="d1"><data key="kind">domain</data><data key="label">B&lt;1&gt; &#34;é&#34;\</data><data key="hidden">true</data></node>`,
+		`<node id="c0"><data key="kind">constraint</data><data key="label">propagator.largerThan</data></node>`,
+		`<edge source="c0" target="d0"/>`,
+	} {
+		if !strings.Contains(graphML.String(), expected) {
+			t.Fatalf("GraphML output is missing %s:\n%s", expected, graphML.String())
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package propagator
+
+import (
+	"context"
+	"log/slog"
+)
+
+// DefaultLogLevels returns the levels at which LogStructured logs the solver events. Events not in the map are not
+// logged.
+func DefaultLogLevels() map[SolverEvent]slog.Level {
+	return map[SolverEvent]slog.Level{
+		Start:                slog.LevelInfo,
+		Finished:             slog.LevelInfo,
+		SolutionFound:        slog.LevelInfo,
+		Failure:              slog.LevelWarn,
+		SearchStart:          slog.LevelDebug,
+		Assign:               slog.LevelDebug,
+		Backtrack:            slog.LevelDebug,
+		Contradiction:        slog.LevelDebug,
+		Select:               slog.LevelDebug - 4,
+		PropagateStart:       slog.LevelDebug - 4,
+		ConstraintPropagated: slog.LevelDebug - 4,
+	}
+}
+
+// LogStructured logs solver events to the given logger at the levels of DefaultLogLevels.
+// Each record has the event as message and attributes for the depth and, where relevant, the domain, index,
+// constraint, reason and solution number.
+func LogStructured(logger *slog.Logger) SolverOption {
+	return LogStructuredAt(logger, DefaultLogLevels())
+}
+
+// LogStructuredAt logs the solver events in levels to the given logger at the given level.
+func LogStructuredAt(logger *slog.Logger, levels map[SolverEvent]slog.Level) SolverOption {
+	return func(s *Solver) {
+		for event, level := range levels {
+			event, level := event, level
+			s.events.Subscribe(event, func(e Event) {
+				ctx := context.Background()
+				if !logger.Enabled(ctx, level) {
+					return
+				}
+				logger.LogAttrs(ctx, level, event, eventAttrs(e)...)
+			})
+		}
+	}
+}
+
+// LogConstraintsStructured logs every constraint of the model to the given logger at the given level when solving
+// is started. Each record has the constraint id, type and linked domain names as attributes.
+func LogConstraintsStructured(logger *slog.Logger, level slog.Level) SolverOption {
+	return func(s *Solver) {
+		s.events.Subscribe(Start, func(e Event) {
+			ctx := context.Background()
+			if !logger.Enabled(ctx, level) {
+				return
+			}
+			for id := range e.Model.constraints {
+				info := e.Model.describeConstraint(id)
+				logger.LogAttrs(
+					ctx,
+					level,
+					"Constraint",
+					slog.Int("constraint_id", info.Id),
+					slog.String("constraint", info.Type),
+					slog.Any("domains", info.Domains),
+				)
+			}
+		})
+	}
+}
+
+func eventAttrs(e Event) []slog.Attr {
+	attrs := []slog.Attr{slog.Int("depth", e.Depth)}
+	if e.Domain != nil {
+		attrs = append(attrs, slog.String("domain", e.Model.domainNames[e.Domain.id]))
+	}
+	if e.Index != -1 {
+		attrs = append(attrs, slog.Int("index", e.Index))
+	}
+	if e.ConstraintId != -1 {
+		attrs = append(
+			attrs,
+			slog.Int("constraint_id", e.ConstraintId),
+			slog.String("constraint", e.Model.describeConstraint(e.ConstraintId).Type),
+		)
+	}
+	if e.Reason != "" {
+		attrs = append(attrs, slog.String("reason", e.Reason))
+	}
+	if e.Solution > 0 {
+		attrs = append(attrs, slog.Int("solution", e.Solution))
+	}
+	return attrs
+}
@@ -0,0 +1,55 @@
+package propagator
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogStructured(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariable(csp, "A", []DomainValue[int]{{0, 1.0, 0}, {1, 1.0, 1}})
+	varB := AddVariable(csp, "B", []DomainValue[int]{{0, 1.0, 1}})
+
+	csp.AddConstraint(constraint{varA, varB})
+
+	model := csp.Model()
+
+	output := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	solver := NewSolver(
+		WithSeed(0),
+		LogStructured(logger),
+		LogConstraintsStructured(logger, slog.LevelInfo),
+	)
+
+	if !solver.Solve(model) {
+		t.Fatalf("failed to find solution")
+	}
+
+	for _, expected := range []string{
+		"level=INFO msg=Start depth=0",
+		"level=INFO msg=Constraint constraint_id=0 constraint=propagator.constraint domains=\"[A B]\"",
+		"level=DEBUG msg=Assign depth=1 domain=A index=0",
+		"level=DEBUG msg=Contradiction depth=1 domain=A constraint_id=0 constraint=propagator.constraint",
+		"level=DEBUG msg=Backtrack depth=1 domain=A index=0",
+		"level=INFO msg=SolutionFound depth=1 solution=1",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Fatalf("log is missing %q:\n%s", expected, output.String())
+		}
+	}
+	if strings.Contains(output.String(), "ConstraintPropagated") {
+		t.Fatalf("log should not contain events below the handler level:\n%s", output.String())
+	}
+}
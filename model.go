@@ -1,8 +1,17 @@
 package propagator
 
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
 // DomainId is a reference to a domain.
 type DomainId = int
 
+// indexArenaChunkSize is the number of indices allocated at once for copying the indices of mutations.
+const indexArenaChunkSize = 4096
+
 // Model holds the tracked variables and the constraints between them.
 type Model struct {
 	// Domains allow access to the indices associated with the variables of this Model.
@@ -10,26 +19,112 @@ type Model struct {
 
 	// domainConstraints allows to look up the constraints that apply to a particular domain.
 	domainConstraints map[DomainId][]constraintId
+	// domainWakes holds the events on which the constraints in domainConstraints are propagated.
+	domainWakes map[DomainId][]Wake
 	// constraints holds all constraints indexed by their constraintId.
 	constraints []boundConstraint
+	// constraintDisabled marks constraints that are left out of propagation, which is used to find explanations.
+	constraintDisabled []bool
+	// constraintEntailed marks constraints that declared themselves entailed, until the mutations leading to it are
+	// reverted.
+	constraintEntailed []bool
 
 	domainHidden           []bool
 	domainNumIndices       []int
 	domainNames            []string
+	domainValues           []func(index int) any
 	domainEntropy          []float64
 	domainVersions         []int
 	domainSumProbability   []float64
 	domainMinPriority      []int
-	domainIndices          [][]*index
 	domainAvailableIndices [][]int
+	// domainWeights holds the probability and priority of each index. The weight of a banned index is left as it was
+	// when it was banned.
+	domainWeights [][]weight
+	// indexModifiers holds the modifiers making up the weights in domainWeights.
+	indexModifiers map[modifierKey]weight
+	// domainAvailable holds the indices that are not banned.
+	domainAvailable []bitset
+	// domainWeighted holds the available indices with a probability other than 1 or a priority other than 0.
+	domainWeighted []bitset
 
 	indexBuffer []int
+	// indexArena holds the unused part of the chunk from which the indices of new mutations are copied.
+	indexArena []int
 }
 
 // boundConstraint defines the link between a Constraint and its related Domains.
 type boundConstraint struct {
 	constraint    Constraint
 	linkedDomains []DomainId
+	cost          PropagationCost
+	// incremental is the constraint as IncrementalConstraint, or nil if it does not implement it.
+	incremental IncrementalConstraint
+}
+
+// describeConstraint returns the ConstraintInfo for the constraint with the given id.
+func (m *Model) describeConstraint(id constraintId) ConstraintInfo {
+	boundConstraint := m.constraints[id]
+	domains := make([]string, 0, len(boundConstraint.linkedDomains))
+	for _, domain := range boundConstraint.linkedDomains {
+		domains = append(domains, m.Domains[domain].Name())
+	}
+	return ConstraintInfo{
+		Id:      id,
+		Type:    reflect.TypeOf(boundConstraint.constraint).String(),
+		Domains: domains,
+	}
+}
+
+// isPropagated returns whether the constraint takes part in propagation, meaning it is neither disabled nor entailed.
+func (m *Model) isPropagated(id constraintId) bool {
+	return !m.constraintDisabled[id] && !m.constraintEntailed[id]
+}
+
+// withConstraints returns a copy of the model with the given constraints added. The copy shares the domains with the
+// model, so changes made while solving either are visible in both, but the added constraints are only propagated when
+// solving the copy.
+func (m Model) withConstraints(constraints ...Constraint) Model {
+	extended := m
+	extended.constraints = slices.Clip(m.constraints)
+	extended.constraintDisabled = slices.Clip(m.constraintDisabled)
+	extended.constraintEntailed = slices.Clip(m.constraintEntailed)
+	extended.domainConstraints = maps.Clone(m.domainConstraints)
+	extended.domainWakes = maps.Clone(m.domainWakes)
+	for id := range extended.domainConstraints {
+		extended.domainConstraints[id] = slices.Clip(extended.domainConstraints[id])
+		extended.domainWakes[id] = slices.Clip(extended.domainWakes[id])
+	}
+
+	for _, constraint := range constraints {
+		id := len(extended.constraints)
+		bound := bindConstraint(constraint)
+		wakes := wakesOf(constraint, bound.linkedDomains)
+
+		extended.constraints = append(extended.constraints, bound)
+		extended.constraintDisabled = append(extended.constraintDisabled, false)
+		extended.constraintEntailed = append(extended.constraintEntailed, false)
+		for i, domain := range bound.linkedDomains {
+			extended.domainConstraints[domain] = append(extended.domainConstraints[domain], id)
+			extended.domainWakes[domain] = append(extended.domainWakes[domain], wakes[i])
+		}
+	}
+	return extended
+}
+
+// copyIndices returns a copy of the indices. Copies are taken from chunks that are shared by many mutations, so
+// creating a mutation does not allocate each time, but the allocations are amortised rather than avoided. Chunks are
+// never reused: a new chunk of indexArenaChunkSize indices, or of the size of a larger copy, is allocated whenever the
+// current one is used up, so the memory allocated grows with the number of indices copied. A chunk is only freed when
+// no mutation refers to it anymore, and released mutators keep their mutations until they are reused.
+func (m *Model) copyIndices(indices []int) []int {
+	if len(indices) > len(m.indexArena) {
+		m.indexArena = make([]int, max(indexArenaChunkSize, len(indices)))
+	}
+	copied := m.indexArena[:len(indices):len(indices)]
+	m.indexArena = m.indexArena[len(indices):]
+	copy(copied, indices)
+	return copied
 }
 
 // IsSolved returns whether this model currently is in a solved state.
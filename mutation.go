@@ -1,20 +1,68 @@
 package propagator
 
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
 // Mutator collects and applies mutations from constraints
 type Mutator struct {
 	activeConstraintId constraintId
-	mutations          []Mutation
-	prevHead           int
-	head               int
+	// activeConstraint and activeScope are only set when scopes are checked strictly.
+	activeConstraint Constraint
+	activeScope      []DomainId
+
+	mutations []Mutation
+	prevHead  int
+	head      int
+	// changes holds the index changes of the applied mutations, of which each mutation refers to its own part.
+	changes []indexChange
+
+	// contradiction holds the first domain that was wiped out by applying the mutations, together with the constraint
+	// that added the mutation. If a constraint failed explicitly, contradiction is nil and failed is set instead.
+	contradiction             *Domain
+	contradictionConstraintId constraintId
+	contradictionReason       string
+	failed                    bool
+
+	// entailments holds the constraints that declared themselves entailed, which are marked in constraintEntailed
+	// when applied.
+	entailments        []constraintId
+	constraintEntailed []bool
+	entailPrevHead     int
+	entailHead         int
+
+	// trail holds the previous values of the reversible state changed through this mutator.
+	trail         []trailEntry
+	trailPrevHead int
+	trailHead     int
 }
 
 // newMutator Creates a new Mutator.
 func newMutator() *Mutator {
 	return &Mutator{
 		activeConstraintId: -1,
+		activeConstraint:   nil,
+		activeScope:        nil,
 		mutations:          make([]Mutation, 0, 10),
 		prevHead:           0,
 		head:               0,
+		changes:            nil,
+
+		contradiction:             nil,
+		contradictionConstraintId: -1,
+		contradictionReason:       "",
+		failed:                    false,
+
+		entailments:        nil,
+		constraintEntailed: nil,
+		entailPrevHead:     0,
+		entailHead:         0,
+
+		trail:         nil,
+		trailPrevHead: 0,
+		trailHead:     0,
 	}
 }
 
@@ -24,23 +72,97 @@ func (m *Mutator) Add(updates ...Mutation) {
 		if update.domain == nil || len(update.indices) == 0 {
 			continue
 		}
+		if m.activeScope != nil && !slices.Contains(m.activeScope, update.domain.id) {
+			panic(fmt.Sprintf(
+				"constraint %d (%s) mutates domain %q outside its scope",
+				m.activeConstraintId,
+				reflect.TypeOf(m.activeConstraint),
+				update.domain.Name(),
+			))
+		}
 		update.constraintId = m.activeConstraintId
 		m.mutations = append(m.mutations, update)
 	}
 }
 
-// setActiveConstraintId is called internally by the solver to notify the mutator of the constraint for which
-// mutations are currently processed.
-func (m *Mutator) setActiveConstraintId(c constraintId) {
+// Fail declares that the constraint being propagated found an inconsistency, for the given reason. Propagation is
+// aborted as soon as the constraint returns, without applying the mutations it added in the same propagation.
+// Fail does nothing when called outside of propagation.
+func (m *Mutator) Fail(reason string) {
+	if m.activeConstraintId == -1 || m.failed {
+		return
+	}
+	m.failed = true
+	m.contradiction = nil
+	m.contradictionConstraintId = m.activeConstraintId
+	m.contradictionReason = reason
+}
+
+// Entail declares that the constraint being propagated is entailed: it holds for every remaining assignment of its
+// scope and will not add mutations anymore. The solver stops propagating the constraint until the mutations leading up
+// to it are reverted. Mutations added by the constraint in the same propagation are still applied.
+// Entail does nothing when called outside of propagation.
+func (m *Mutator) Entail() {
+	if m.activeConstraintId == -1 {
+		return
+	}
+	m.entailments = append(m.entailments, m.activeConstraintId)
+}
+
+// setActiveConstraint is called internally by the solver to notify the mutator of the constraint for which
+// mutations are currently processed. If strict is set, mutations outside the constraint scope are rejected.
+func (m *Mutator) setActiveConstraint(model Model, c constraintId, strict bool) {
 	m.activeConstraintId = c
+	m.constraintEntailed = model.constraintEntailed
+	m.activeConstraint = nil
+	m.activeScope = nil
+	if strict {
+		m.activeConstraint = model.constraints[c].constraint
+		m.activeScope = model.constraints[c].linkedDomains
+	}
+}
+
+// clearActiveConstraint is called internally by the solver when the active constraint is done propagating. Mutations
+// added afterwards are not attributed to any constraint.
+func (m *Mutator) clearActiveConstraint() {
+	m.activeConstraintId = -1
+	m.activeConstraint = nil
+	m.activeScope = nil
 }
 
 func (m *Mutator) apply() {
 	m.prevHead = m.head
 	for m.head < len(m.mutations) {
-		m.mutations[m.head].apply()
+		mutation := &m.mutations[m.head]
+		var isChanged bool
+		m.changes, isChanged = mutation.apply(m.changes)
+		if isChanged && m.contradiction == nil && mutation.domain.IsInContradiction() {
+			m.contradiction = mutation.domain
+			m.contradictionConstraintId = mutation.constraintId
+		}
 		m.head++
 	}
+
+	m.entailPrevHead = m.entailHead
+	for m.entailHead < len(m.entailments) {
+		m.constraintEntailed[m.entailments[m.entailHead]] = true
+		m.entailHead++
+	}
+
+	m.trailPrevHead = m.trailHead
+	m.trailHead = len(m.trail)
+}
+
+// discardPending drops the mutations and entailments that were added since the last apply.
+func (m *Mutator) discardPending() {
+	m.mutations = m.mutations[:m.head]
+	m.entailments = m.entailments[:m.entailHead]
+}
+
+// record is called by reversible state when it is changed through this mutator, so the old value can be restored.
+// Changes recorded after the last apply are reverted together with the mutations of the next apply.
+func (m *Mutator) record(target reversible, old int) {
+	m.trail = append(m.trail, trailEntry{target: target, old: old})
 }
 
 func (m *Mutator) revertAll() {
@@ -49,14 +171,56 @@ func (m *Mutator) revertAll() {
 		m.mutations[m.head].revert()
 	}
 	m.mutations = m.mutations[:0]
+	m.changes = m.changes[:0]
+	m.revertEntailments(0)
+	m.revertTrail(0)
+	m.resetContradiction()
 }
 
 func (m *Mutator) revertPrevious() {
 	for m.head > m.prevHead {
 		m.head--
 		m.mutations[m.head].revert()
+		m.changes = m.changes[:len(m.changes)-len(m.mutations[m.head].changes)]
 		m.mutations = m.mutations[:m.head]
 	}
+	m.revertEntailments(m.entailPrevHead)
+	m.revertTrail(m.trailPrevHead)
+	m.resetContradiction()
+}
+
+// reset reverts all mutations and returns the mutator to its initial state, keeping its buffers for reuse.
+func (m *Mutator) reset() {
+	m.revertAll()
+	m.clearActiveConstraint()
+	m.prevHead = 0
+	m.entailPrevHead = 0
+	m.trailPrevHead = 0
+}
+
+// revertTrail restores the reversible state changed after the given head, in reverse order of change.
+func (m *Mutator) revertTrail(head int) {
+	for i := len(m.trail) - 1; i >= head; i-- {
+		m.trail[i].target.restore(m.trail[i].old)
+	}
+	m.trail = m.trail[:head]
+	m.trailHead = head
+}
+
+// revertEntailments unmarks the constraints that were entailed after the given head.
+func (m *Mutator) revertEntailments(head int) {
+	for m.entailHead > head {
+		m.entailHead--
+		m.constraintEntailed[m.entailments[m.entailHead]] = false
+	}
+	m.entailments = m.entailments[:head]
+}
+
+func (m *Mutator) resetContradiction() {
+	m.contradiction = nil
+	m.contradictionConstraintId = -1
+	m.contradictionReason = ""
+	m.failed = false
 }
 
 // Mutation defines a mutation to the probability and priority set for the indices of a Domain.
@@ -66,25 +230,35 @@ type Mutation struct {
 	probability float64
 	priority    int
 
-	constraintId   constraintId
-	reverseIndices []reverseIndex
+	constraintId constraintId
+	// changes are the index changes made when this mutation was last applied.
+	changes []indexChange
+	// removed is the number of indices banned when this mutation was last applied.
+	removed int
+	// events are the domain events caused when this mutation was last applied.
+	events Wake
 }
 
 // DoNothing is the update that changes nothing to a domain.
 var DoNothing = Mutation{}
 
-// reverseIndex stores an index id together with the previous index, so it can later be reversed.
-type reverseIndex struct {
-	id  int
-	old *index
-}
+// apply applies the changes defined by this mutation and appends the changed indices to changes, so they can be
+// reverted. It returns the extended changes and whether any index was changed.
+func (u *Mutation) apply(changes []indexChange) ([]indexChange, bool) {
+	start := len(changes)
+	u.removed = 0
+	u.events = 0
+
+	available := u.domain.AvailableIndices()
+	numAvailable := len(available)
+	lowest, highest := -1, -1
+	if numAvailable > 0 {
+		lowest, highest = available[0], available[numAvailable-1]
+	}
 
-// apply applies the changes defined by this mutation and tracks the changed indices, so they can be reverted.
-func (u *Mutation) apply() {
-	u.reverseIndices = make([]reverseIndex, 0, len(u.indices))
 	for _, i := range u.indices {
-		oldIndex := u.domain.getIndex(i)
-		newIndex, isUpdated := oldIndex.adjust(
+		change, isUpdated := u.domain.adjust(
+			i,
 			u.constraintId,
 			u.probability,
 			u.priority,
@@ -93,23 +267,52 @@ func (u *Mutation) apply() {
 			continue
 		}
 
-		u.reverseIndices = append(u.reverseIndices, reverseIndex{id: i, old: oldIndex})
-		u.domain.setIndex(i, newIndex)
+		changes = append(changes, change)
+		if change.banned {
+			u.removed++
+		}
+	}
+
+	u.changes = changes[start:len(changes):len(changes)]
+	if len(u.changes) == 0 {
+		return changes, false
+	}
+
+	u.domain.update()
+	u.events = u.domainEvents(numAvailable, lowest, highest)
+	return changes, true
+}
+
+// domainEvents returns the events caused by this mutation, given the available indices of the domain before applying
+// it.
+func (u *Mutation) domainEvents(numAvailable, lowest, highest int) Wake {
+	var events Wake
+	if len(u.changes) > u.removed {
+		events |= WakeOnWeights
+	}
+	if u.removed == 0 {
+		return events
 	}
 
-	if len(u.reverseIndices) > 0 {
-		u.domain.update()
+	events |= WakeOnDomain
+	available := u.domain.AvailableIndices()
+	if len(available) == 1 && numAvailable > 1 {
+		events |= WakeOnAssign
+	}
+	if len(available) == 0 || available[0] != lowest || available[len(available)-1] != highest {
+		events |= WakeOnBounds
 	}
+	return events
 }
 
 // revert reverts the changes done by this mutation.
 func (u *Mutation) revert() {
-	if len(u.reverseIndices) == 0 {
+	if len(u.changes) == 0 {
 		return
 	}
 
-	for _, revIdx := range u.reverseIndices {
-		u.domain.setIndex(revIdx.id, revIdx.old)
+	for i := len(u.changes) - 1; i >= 0; i-- {
+		u.domain.revertChange(u.constraintId, u.changes[i])
 	}
 
 	u.domain.update()
@@ -2,6 +2,7 @@ package propagator
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -47,3 +48,106 @@ func TestMutation(t *testing.T) {
 		t.Errorf("expected version to be 3 after revert")
 	}
 }
+
+func TestMutation_Events(t *testing.T) {
+	csp := NewProblem()
+	domain := AddVariableFromValues(csp, "test", []int{1, 2, 3, 4})
+
+	csp.Model()
+
+	mutator := newMutator()
+
+	steps := []struct {
+		mutation Mutation
+		expected Wake
+	}{
+		{domain.UpdateProbability(0.5, 0), WakeOnWeights},
+		{domain.Exclude(1), WakeOnDomain},
+		{domain.Exclude(3), WakeOnDomain | WakeOnBounds},
+		{domain.Exclude(0), WakeOnDomain | WakeOnBounds | WakeOnAssign},
+	}
+
+	for i, step := range steps {
+		mutator.Add(step.mutation)
+		mutator.apply()
+		if events := mutator.mutations[i].events; events != step.expected {
+			t.Errorf("wrong events for mutation %d: expected %04b, got %04b", i, step.expected, events)
+		}
+	}
+}
+
+func TestMutator_Entail(t *testing.T) {
+	csp := NewProblem()
+	domain := AddVariableFromValues(csp, "test", []int{1, 2, 3})
+	csp.AddConstraint(constraint{domain, domain})
+
+	model := csp.Model()
+
+	mutator := newMutator()
+
+	mutator.Entail()
+	mutator.apply()
+	if model.constraintEntailed[0] {
+		t.Fatalf("expected entail outside of propagation to be ignored")
+	}
+
+	mutator.setActiveConstraint(model, 0, false)
+	mutator.Add(domain.Exclude(0))
+	mutator.apply()
+	mutator.Entail()
+	mutator.apply()
+	if !model.constraintEntailed[0] {
+		t.Fatalf("expected constraint to be entailed after applying")
+	}
+
+	mutator.revertPrevious()
+	if model.constraintEntailed[0] {
+		t.Fatalf("expected entailment to be reverted")
+	}
+	if len(domain.AvailableIndices()) != 2 {
+		t.Fatalf("expected earlier mutations to remain applied")
+	}
+
+	mutator.Entail()
+	mutator.apply()
+	mutator.revertAll()
+	if model.constraintEntailed[0] {
+		t.Fatalf("expected entailment to be reverted")
+	}
+}
+
+func TestMutator_StrictScopes(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2})
+
+	csp.AddConstraint(outOfScope{varA, varB})
+
+	model := csp.Model()
+
+	solver := NewSolver(StrictScopes())
+
+	defer func() {
+		r := recover()
+		message, ok := r.(string)
+		if !ok || !strings.Contains(message, "propagator.outOfScope") || !strings.Contains(message, `"B"`) {
+			t.Fatalf("expected panic naming constraint and domain, got %v", r)
+		}
+	}()
+
+	solver.Solve(model)
+}
+
+// outOfScope has only a in scope, but mutates b.
+type outOfScope struct {
+	a *Variable[int]
+	b *Variable[int]
+}
+
+func (o outOfScope) Scope() []DomainId {
+	return IdsOf(o.a)
+}
+
+func (o outOfScope) Propagate(m *Mutator) {
+	m.Add(o.b.Exclude(0))
+}
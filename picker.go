@@ -47,7 +47,7 @@ func (p *MinRemainingValuesPicker) nextDomain(m Model) *Domain {
 		return p.candidates[0]
 	}
 
-	maxConstraints := 0
+	maxConstraints := -1
 	var nextDomain *Domain
 	for _, candidate := range p.candidates {
 		constraintCount := candidate.numRelevantConstraints()
@@ -100,7 +100,8 @@ func (p *IndexDomainPicker) nextDomain(m Model) *Domain {
 
 // RandomDomainPicker selects the next unassigned domain at random.
 type RandomDomainPicker struct {
-	rnd *rand.Rand
+	rnd          *rand.Rand
+	validDomains []*Domain
 }
 
 func (p *RandomDomainPicker) init(m Model, rnd *rand.Rand) {
@@ -108,13 +109,16 @@ func (p *RandomDomainPicker) init(m Model, rnd *rand.Rand) {
 }
 
 func (p *RandomDomainPicker) nextDomain(m Model) *Domain {
-	var validDomains []*Domain
+	p.validDomains = p.validDomains[:0]
 	for _, domain := range m.Domains {
 		if domain.CanBePicked() {
-			validDomains = append(validDomains, domain)
+			p.validDomains = append(p.validDomains, domain)
 		}
 	}
-	return validDomains[p.rnd.Intn(len(validDomains))]
+	if len(p.validDomains) == 0 {
+		return nil
+	}
+	return p.validDomains[p.rnd.Intn(len(p.validDomains))]
 }
 
 // indexPicker selects the next index from a given domain.
@@ -177,17 +181,17 @@ func (p *ProbabilisticIndexPicker) nextIndex(d *Domain) int {
 
 	probSum := 0.0
 	prev := 0.0
-	for i := 0; i < d.numIndices(); i++ {
-		idx := d.getIndex(i)
-		if idx.isBanned || idx.priority != minPriority {
+	weights := d.weights()
+	for _, i := range d.AvailableIndices() {
+		if weights[i].priority != minPriority {
 			continue
 		}
 
 		p.cdfIdx = append(p.cdfIdx, i)
-		nextProb := prev + idx.probability
+		nextProb := prev + weights[i].probability
 		p.cdf = append(p.cdf, nextProb)
 		prev = nextProb
-		probSum += idx.probability
+		probSum += weights[i].probability
 	}
 
 	if len(p.cdf) == 0 {
@@ -0,0 +1,36 @@
+package propagator
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMinRemainingValuesPicker_NoConstraints(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	AddVariableFromValues(csp, "B", []int{1, 2})
+
+	model := csp.Model()
+
+	picker := &MinRemainingValuesPicker{}
+	picker.init(model, rand.New(rand.NewSource(0)))
+
+	if domain := picker.nextDomain(model); domain == nil || domain.Name() != varA.Name() {
+		t.Fatalf("expected domain A to be picked, got %v", domain)
+	}
+}
+
+func TestRandomDomainPicker_NoDomainCanBePicked(t *testing.T) {
+	csp := NewProblem()
+	AddVariableFromValues(csp, "A", []int{1})
+	AddHiddenVariableFromValues(csp, "B", []int{1, 2})
+
+	model := csp.Model()
+
+	picker := &RandomDomainPicker{}
+	picker.init(model, rand.New(rand.NewSource(0)))
+
+	if domain := picker.nextDomain(model); domain != nil {
+		t.Fatalf("expected no domain to be picked, got %s", domain.Name())
+	}
+}
@@ -2,10 +2,27 @@
 package propagator
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"slices"
 )
 
+var (
+	// ErrEmptyScope is reported for a constraint without domains in its scope.
+	ErrEmptyScope = errors.New("constraint scope contains no domains")
+	// ErrUnknownDomain is reported for a constraint scope referring to a domain that is not part of the problem.
+	ErrUnknownDomain = errors.New("constraint scope contains unknown domain")
+	// ErrHiddenScope is reported for a constraint that only has hidden domains in its scope.
+	ErrHiddenScope = errors.New("constraint scope contains only hidden domains")
+	// ErrDuplicateName is reported for a variable with the same name as an earlier variable.
+	ErrDuplicateName = errors.New("duplicate variable name")
+	// ErrEmptyDomain is reported for a variable without values.
+	ErrEmptyDomain = errors.New("variable has no values")
+	// ErrZeroProbability is reported for a variable of which all values have zero probability.
+	ErrZeroProbability = errors.New("variable values all have zero probability")
+)
+
 // Problem holds information about a constraint satisfaction problem under construction.
 // Use NewProblem to start defining a new problem.
 type Problem struct {
@@ -13,10 +30,12 @@ type Problem struct {
 	domains                []*Domain
 	nextDomainId           DomainId
 	domainNames            []string
+	domainValues           []func(index int) any
 	domainHidden           []bool
-	domainIndices          [][]*index
+	domainWeights          [][]weight
 	domainAvailableIndices [][]int
 	domainConstraints      map[DomainId][]constraintId
+	domainWakes            map[DomainId][]Wake
 	constraints            []boundConstraint
 }
 
@@ -27,9 +46,11 @@ func NewProblem() *Problem {
 		domains:                []*Domain{},
 		nextDomainId:           0,
 		domainNames:            []string{},
+		domainValues:           []func(index int) any{},
 		domainHidden:           []bool{},
 		domainConstraints:      make(map[DomainId][]constraintId),
-		domainIndices:          [][]*index{},
+		domainWakes:            make(map[DomainId][]Wake),
+		domainWeights:          [][]weight{},
 		domainAvailableIndices: [][]int{},
 		constraints:            []boundConstraint{},
 	}
@@ -51,9 +72,97 @@ func AsDomainValues[T comparable](values ...T) []DomainValue[T] {
 	return domainValues
 }
 
-// Model returns the initialized model.
+// Validate checks the problem definition and returns all problems found, joined into a single error. Each problem
+// wraps one of the Err* errors, so it can be inspected with errors.Is. It returns nil if the problem is valid.
+// Domain ids are only recognized as unknown when they are out of range, so scopes using domains of another Problem
+// may go unnoticed.
+func (c *Problem) Validate() error {
+	return errors.Join(append(c.validateVariables(), c.validateConstraints()...)...)
+}
+
+// validateVariables returns the problems with the variable definitions.
+func (c *Problem) validateVariables() []error {
+	var errs []error
+
+	names := make(map[string]bool, len(c.domainNames))
+	for id, name := range c.domainNames {
+		if names[name] {
+			errs = append(errs, fmt.Errorf("%w: %q (domain %d)", ErrDuplicateName, name, id))
+		}
+		names[name] = true
+
+		if len(c.domainWeights[id]) == 0 {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrEmptyDomain, name))
+			continue
+		}
+		allBanned := true
+		for _, w := range c.domainWeights[id] {
+			if !isBannedProbability(w.probability) {
+				allBanned = false
+				break
+			}
+		}
+		if allBanned {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrZeroProbability, name))
+		}
+	}
+
+	return errs
+}
+
+// validateConstraints returns the problems with the constraint scopes.
+func (c *Problem) validateConstraints() []error {
+	var errs []error
+
+	for id, constraint := range c.constraints {
+		constraintType := fmt.Sprintf("%T", constraint.constraint)
+		if len(constraint.linkedDomains) == 0 {
+			errs = append(errs, fmt.Errorf("%w: constraint %d (%s)", ErrEmptyScope, id, constraintType))
+			continue
+		}
+		isHidden := true
+		isKnown := true
+		for _, domain := range constraint.linkedDomains {
+			if domain < 0 || domain >= c.nextDomainId {
+				errs = append(errs, fmt.Errorf("%w: constraint %d (%s) refers to domain %d", ErrUnknownDomain, id, constraintType, domain))
+				isKnown = false
+				continue
+			}
+			if !c.domainHidden[domain] {
+				isHidden = false
+			}
+		}
+		if isKnown && isHidden {
+			errs = append(errs, fmt.Errorf("%w: constraint %d (%s)", ErrHiddenScope, id, constraintType))
+		}
+	}
+
+	return errs
+}
+
+// Build validates the problem and returns the initialized model, or the validation error if the problem is invalid.
+// This should be called after the problem is completely defined.
+func (c *Problem) Build() (Model, error) {
+	if err := c.Validate(); err != nil {
+		return Model{}, err
+	}
+	return c.build(), nil
+}
+
+// Model returns the initialized model without validating the problem. It only panics if a constraint scope is empty
+// or refers to unknown domains, as the model cannot be used then; use Build to check the whole problem.
 // This should be called after the problem is completely defined.
 func (c *Problem) Model() Model {
+	for _, err := range c.validateConstraints() {
+		if errors.Is(err, ErrEmptyScope) || errors.Is(err, ErrUnknownDomain) {
+			panic(err)
+		}
+	}
+	return c.build()
+}
+
+// build initializes the model.
+func (c *Problem) build() Model {
 	numDomains := c.nextDomainId
 
 	domainNumIndices := make([]int, numDomains)
@@ -61,9 +170,15 @@ func (c *Problem) Model() Model {
 	domainVersions := make([]int, numDomains)
 	domainSumProbability := make([]float64, numDomains)
 	domainMinPriority := make([]int, numDomains)
+	domainAvailable := make([]bitset, numDomains)
+	domainWeighted := make([]bitset, numDomains)
+	domainWeights := make([][]weight, numDomains)
 
 	for i := 0; i < numDomains; i++ {
-		domainNumIndices[i] = len(c.domainIndices[i])
+		domainNumIndices[i] = len(c.domainWeights[i])
+		domainWeights[i] = slices.Clone(c.domainWeights[i])
+		domainAvailable[i] = newBitset(domainNumIndices[i])
+		domainWeighted[i] = newBitset(domainNumIndices[i])
 		domainEntropy[i] = math.Inf(+1)
 		domainVersions[i] = 0
 		domainSumProbability[i] = 0.0
@@ -72,19 +187,33 @@ func (c *Problem) Model() Model {
 
 	c.model.Domains = c.domains
 	c.model.domainConstraints = c.domainConstraints
+	c.model.domainWakes = c.domainWakes
 	c.model.constraints = c.constraints
+	c.model.constraintDisabled = make([]bool, len(c.constraints))
+	c.model.constraintEntailed = make([]bool, len(c.constraints))
 	c.model.domainNumIndices = domainNumIndices
 	c.model.domainNames = c.domainNames
+	c.model.domainValues = c.domainValues
 	c.model.domainEntropy = domainEntropy
 	c.model.domainVersions = domainVersions
 	c.model.domainSumProbability = domainSumProbability
 	c.model.domainMinPriority = domainMinPriority
-	c.model.domainIndices = c.domainIndices
+	c.model.domainWeights = domainWeights
+	c.model.indexModifiers = make(map[modifierKey]weight)
 	c.model.domainHidden = c.domainHidden
 	c.model.domainAvailableIndices = c.domainAvailableIndices
+	c.model.domainAvailable = domainAvailable
+	c.model.domainWeighted = domainWeighted
 	c.model.indexBuffer = make([]int, 0, slices.Max(domainNumIndices))
 
 	for _, domain := range c.domains {
+		for i, w := range domain.weights() {
+			isBanned := isBannedProbability(w.probability)
+			if !isBanned && w != defaultWeight {
+				c.model.indexModifiers[modifierKey{domain: domain.id, index: i, constraint: -1}] = w
+			}
+			domain.setIndex(i, w, !isBanned)
+		}
 		domain.update()
 	}
 
@@ -92,18 +221,66 @@ func (c *Problem) Model() Model {
 }
 
 // AddConstraint adds a constraint to the Problem definition.
+// Invalid scopes are reported when the problem is validated.
 func (c *Problem) AddConstraint(constraint Constraint) {
 	index := len(c.constraints)
-	domainsInScope := constraint.Scope()
-	if len(domainsInScope) == 0 {
-		panic("constraint scope contains no Domains")
-	}
+	bound := bindConstraint(constraint)
+	domainsInScope := bound.linkedDomains
 
-	c.constraints = append(c.constraints, boundConstraint{constraint, domainsInScope})
-	for _, domainInScope := range domainsInScope {
+	wakes := wakesOf(constraint, domainsInScope)
+
+	c.constraints = append(c.constraints, bound)
+	for i, domainInScope := range domainsInScope {
 		constraintLinks := c.domainConstraints[domainInScope]
 		constraintLinks = append(constraintLinks, index)
 		c.domainConstraints[domainInScope] = constraintLinks
+		c.domainWakes[domainInScope] = append(c.domainWakes[domainInScope], wakes[i])
+	}
+}
+
+// bindConstraint links the constraint to the domains in its scope.
+func bindConstraint(constraint Constraint) boundConstraint {
+	scope := constraint.Scope()
+	incremental, _ := constraint.(IncrementalConstraint)
+	return boundConstraint{
+		constraint,
+		scope,
+		costOf(constraint, scope),
+		incremental,
+	}
+}
+
+// wakesOf returns the events on which the constraint is propagated for each domain in scope.
+func wakesOf(constraint Constraint, scope []DomainId) []Wake {
+	wakes := make([]Wake, len(scope))
+	watching, ok := constraint.(WatchingConstraint)
+	if !ok {
+		for i := range wakes {
+			wakes[i] = WakeOnAny
+		}
+		return wakes
+	}
+
+	watches := watching.Watches()
+	if len(watches) != len(scope) {
+		panic("constraint watches do not match its scope")
+	}
+	copy(wakes, watches)
+	return wakes
+}
+
+// costOf returns the PropagationCost of the constraint.
+func costOf(constraint Constraint, scope []DomainId) PropagationCost {
+	if costed, ok := constraint.(CostedConstraint); ok {
+		return min(max(costed.Cost(), CostUnary), CostExpensive)
+	}
+	switch len(scope) {
+	case 1:
+		return CostUnary
+	case 2:
+		return CostBinary
+	default:
+		return CostLinear
 	}
 }
 
@@ -140,10 +317,10 @@ func AddHiddenVariableFromValues[T comparable](csp *Problem, name string, values
 // newVariable builds a new variable definition bound to the given problem.
 func newVariable[T comparable](csp *Problem, name string, initialValues []DomainValue[T], hidden bool) *Variable[T] {
 	values := make([]T, len(initialValues))
-	indices := make([]*index, len(initialValues))
+	weights := make([]weight, len(initialValues))
 
 	for idx, value := range initialValues {
-		indices[idx] = indexFactorySingleton.create(value.Probability, value.Priority)
+		weights[idx] = weight{probability: value.Probability, priority: value.Priority}
 		values[idx] = value.Value
 	}
 
@@ -162,8 +339,9 @@ func newVariable[T comparable](csp *Problem, name string, initialValues []Domain
 	csp.nextDomainId++
 	csp.domains = append(csp.domains, &domain)
 	csp.domainNames = append(csp.domainNames, name)
-	csp.domainIndices = append(csp.domainIndices, indices)
-	csp.domainAvailableIndices = append(csp.domainAvailableIndices, make([]int, 0, len(indices)))
+	csp.domainValues = append(csp.domainValues, func(index int) any { return values[index] })
+	csp.domainWeights = append(csp.domainWeights, weights)
+	csp.domainAvailableIndices = append(csp.domainAvailableIndices, make([]int, 0, len(weights)))
 	csp.domainHidden = append(csp.domainHidden, hidden)
 
 	return variable
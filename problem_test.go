@@ -0,0 +1,127 @@
+package propagator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestProblem_Validate(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddVariableFromValues(csp, "A", []int{1, 2})
+	AddVariableFromValues(csp, "C", []int{})
+	AddVariable(csp, "D", []DomainValue[int]{{0, 0.0, 1}})
+	varE := AddHiddenVariableFromValues(csp, "E", []int{1, 2})
+
+	csp.AddConstraint(largerThan{varA, varB})
+	csp.AddConstraint(scope{})
+	csp.AddConstraint(scope{IdOf(varA), 7})
+	csp.AddConstraint(scope{IdOf(varE)})
+
+	err := csp.Validate()
+
+	for _, expected := range []error{
+		ErrDuplicateName,
+		ErrEmptyDomain,
+		ErrZeroProbability,
+		ErrEmptyScope,
+		ErrUnknownDomain,
+		ErrHiddenScope,
+	} {
+		if !errors.Is(err, expected) {
+			t.Errorf("expected error %q in %v", expected, err)
+		}
+	}
+
+	if _, err := csp.Build(); err == nil {
+		t.Errorf("expected build to fail")
+	}
+}
+
+func TestProblem_Build(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2})
+
+	csp.AddConstraint(largerThan{varA, varB})
+
+	model, err := csp.Build()
+	if err != nil {
+		t.Fatalf("expected valid problem: %v", err)
+	}
+
+	solver := NewSolver(WithSeed(0))
+	if !solver.Solve(model) {
+		t.Fatalf("failed to find solution")
+	}
+}
+
+func TestProblem_Model_EmptyScope(t *testing.T) {
+	csp := NewProblem()
+	AddVariableFromValues(csp, "A", []int{1, 2})
+
+	csp.AddConstraint(scope{})
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected panic on empty scope")
+		}
+	}()
+
+	csp.Model()
+}
+
+// scope is a constraint with the given scope that does nothing.
+type scope []DomainId
+
+func (s scope) Scope() []DomainId {
+	return s
+}
+
+func (s scope) Propagate(m *Mutator) {}
+
+func TestProblem_Concurrent(t *testing.T) {
+	const numProblems = 8
+
+	solutions := make([][]int, numProblems)
+	var wg sync.WaitGroup
+	for p := 0; p < numProblems; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+
+			csp := NewProblem()
+			values := []DomainValue[int]{{0, 0.5, 1}, {1, 1.0, 2}, {0, 0.25, 3}, {2, 0.75, 4}}
+			variables := make([]*Variable[int], 0, len(values))
+			for i := range values {
+				variables = append(variables, AddVariable(csp, fmt.Sprintf("v%d", i), values))
+			}
+			csp.AddConstraint(distinct{variables, NewReversibleSparseSet(len(values) + 1)})
+
+			model, err := csp.Build()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			solver := NewSolver(WithSeed(0))
+			if !solver.Solve(model) {
+				t.Errorf("problem %d should be solved", p)
+				return
+			}
+			for _, variable := range variables {
+				solutions[p] = append(solutions[p], variable.GetAssignedValue())
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	for p := 1; p < numProblems; p++ {
+		if !slices.Equal(solutions[p], solutions[0]) {
+			t.Fatalf("problems with the same seed should have the same solution: %v and %v", solutions[0], solutions[p])
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package propagator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Profiler records propagation statistics per constraint. Attach it to a solver using ProfileConstraints.
+// Statistics are reset every time the solver starts solving. The searches made by SolveWithAssumptions to find
+// conflicting assumptions and by Explain are recorded as well.
+type Profiler struct {
+	ctx      context.Context
+	stats    []ConstraintStats
+	labelled []context.Context
+}
+
+// ConstraintStats holds the propagation statistics of a single constraint or of all constraints of a type.
+type ConstraintStats struct {
+	// Id is the id of the constraint, or -1 for statistics of a constraint type.
+	Id int
+	// Type is the name of the Go type implementing the constraint.
+	Type string
+	// Calls is the number of times Propagate was called.
+	Calls int
+	// Duration is the total time spent in Propagate.
+	Duration time.Duration
+	// IndicesRemoved is the number of indices banned by the mutations of the constraint.
+	IndicesRemoved int
+	// Contradictions is the number of times a mutation of the constraint wiped out a domain.
+	Contradictions int
+}
+
+// NewProfiler creates a new Profiler. While a constraint propagates, the profiler sets the pprof label "constraint"
+// to the constraint type on top of the labels in ctx, so CPU profiles can be split by constraint type. Afterwards the
+// goroutine labels are set back to those of ctx, as the labels the goroutine had before cannot be read. When solving
+// with other labels, for instance inside pprof.Do, use Do to pass their context.
+func NewProfiler(ctx context.Context) *Profiler {
+	return &Profiler{
+		ctx:      ctx,
+		stats:    []ConstraintStats{},
+		labelled: []context.Context{},
+	}
+}
+
+// Do calls f, typically solving the model, with the profiler using ctx instead of the context passed to NewProfiler.
+// This keeps the labels of ctx on the goroutine while solving inside pprof.Do:
+//
+//	pprof.Do(ctx, pprof.Labels("request", id), func(ctx context.Context) {
+//		profiler.Do(ctx, func() { solver.Solve(model) })
+//	})
+func (p *Profiler) Do(ctx context.Context, f func()) {
+	parent := p.ctx
+	p.ctx = ctx
+	defer func() { p.ctx = parent }()
+
+	f()
+}
+
+// ProfileConstraints records propagation statistics in the given Profiler.
+func ProfileConstraints(profiler *Profiler) SolverOption {
+	return func(s *Solver) {
+		s.evaluator.profiler = profiler
+		s.events.Subscribe(Start, func(e Event) { profiler.init(e.Model) })
+	}
+}
+
+// Stats returns the statistics per constraint, indexed by constraint id.
+func (p *Profiler) Stats() []ConstraintStats {
+	stats := make([]ConstraintStats, len(p.stats))
+	copy(stats, p.stats)
+	return stats
+}
+
+// TypeStats returns the statistics summed per constraint type, ordered by descending duration.
+func (p *Profiler) TypeStats() []ConstraintStats {
+	var stats []ConstraintStats
+	index := make(map[string]int)
+	for _, constraintStats := range p.stats {
+		i, has := index[constraintStats.Type]
+		if !has {
+			i = len(stats)
+			index[constraintStats.Type] = i
+			stats = append(stats, ConstraintStats{Id: -1, Type: constraintStats.Type})
+		}
+		stats[i].Calls += constraintStats.Calls
+		stats[i].Duration += constraintStats.Duration
+		stats[i].IndicesRemoved += constraintStats.IndicesRemoved
+		stats[i].Contradictions += constraintStats.Contradictions
+	}
+	sortByDuration(stats)
+	return stats
+}
+
+// WriteTable writes the statistics per constraint to w as a table, ordered by descending duration.
+func (p *Profiler) WriteTable(w io.Writer) error {
+	stats := p.Stats()
+	sortByDuration(stats)
+	return writeStatsTable(w, stats)
+}
+
+// WriteTypeTable writes the statistics per constraint type to w as a table, ordered by descending duration.
+func (p *Profiler) WriteTypeTable(w io.Writer) error {
+	return writeStatsTable(w, p.TypeStats())
+}
+
+func (p *Profiler) init(m Model) {
+	p.stats = make([]ConstraintStats, len(m.constraints))
+	p.labelled = make([]context.Context, len(m.constraints))
+	for id := range m.constraints {
+		constraintType := m.describeConstraint(id).Type
+		p.stats[id] = ConstraintStats{Id: id, Type: constraintType}
+		p.labelled[id] = pprof.WithLabels(p.ctx, pprof.Labels("constraint", constraintType))
+	}
+}
+
+// initOnce initializes the profiler for the model, unless it already holds statistics for it. Searches made on behalf
+// of a solve, such as finding conflicting assumptions, then add to the statistics of that solve.
+func (p *Profiler) initOnce(m Model) {
+	if len(p.stats) != len(m.constraints) {
+		p.init(m)
+	}
+}
+
+// propagate calls propagate for the constraint, recording the call and its duration. The call is recorded and the
+// labels are reset even if the constraint panics.
+func (p *Profiler) propagate(id constraintId, propagate func()) {
+	pprof.SetGoroutineLabels(p.labelled[id])
+	start := time.Now()
+	defer func() {
+		p.stats[id].Duration += time.Since(start)
+		p.stats[id].Calls++
+		pprof.SetGoroutineLabels(p.ctx)
+	}()
+
+	propagate()
+}
+
+// applied records the indices removed by the mutations the mutator applied starting from head.
+func (p *Profiler) applied(mutator *Mutator, head int) {
+	for _, mutation := range mutator.mutations[head:mutator.head] {
+		if mutation.constraintId == -1 {
+			continue
+		}
+		p.stats[mutation.constraintId].IndicesRemoved += mutation.removed
+	}
+}
+
+// contradiction records the constraint that caused the contradiction of the mutator.
+func (p *Profiler) contradiction(mutator *Mutator) {
+	if mutator.contradictionConstraintId == -1 {
+		return
+	}
+	p.stats[mutator.contradictionConstraintId].Contradictions++
+}
+
+func sortByDuration(stats []ConstraintStats) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Duration > stats[j].Duration
+	})
+}
+
+func writeStatsTable(w io.Writer, stats []ConstraintStats) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(table, "ID\tTYPE\tCALLS\tTIME\tREMOVED\tCONTRADICTIONS\t\n")
+	for _, s := range stats {
+		id := "-"
+		if s.Id != -1 {
+			id = fmt.Sprintf("%d", s.Id)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%d\t%s\t%d\t%d\t\n", id, s.Type, s.Calls, s.Duration, s.IndicesRemoved, s.Contradictions)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,123 @@
+package propagator
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+func TestProfiler(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+	varC := AddVariableFromValues(csp, "C", []int{1, 2, 3})
+	varD := AddVariable(csp, "D", []DomainValue[int]{{0, 1.0, 0}, {1, 1.0, 1}})
+	varE := AddVariable(csp, "E", []DomainValue[int]{{0, 1.0, 1}})
+
+	csp.AddConstraint(largerThan{varA, varB})
+	csp.AddConstraint(largerThan{varB, varC})
+	csp.AddConstraint(constraint{varD, varE})
+
+	model := csp.Model()
+
+	profiler := NewProfiler(context.Background())
+
+	solver := NewSolver(
+		WithSeed(0),
+		SelectDomainsByIndex(),
+		ProfileConstraints(profiler),
+	)
+
+	if !solver.Solve(model) {
+		t.Fatalf("failed to find solution")
+	}
+
+	stats := profiler.Stats()
+	if len(stats) != 3 {
+		t.Fatalf("expected stats for every constraint: %v", stats)
+	}
+	if stats[0].Calls == 0 || stats[0].Type != "propagator.largerThan" || stats[0].Id != 0 {
+		t.Fatalf("wrong stats for first constraint: %+v", stats[0])
+	}
+	if stats[0].IndicesRemoved+stats[1].IndicesRemoved != 6 {
+		t.Fatalf("wrong number of removed indices: %d %d", stats[0].IndicesRemoved, stats[1].IndicesRemoved)
+	}
+	if stats[2].Contradictions != 1 || stats[0].Contradictions != 0 {
+		t.Fatalf("wrong number of contradictions: %+v", stats)
+	}
+
+	typeStats := profiler.TypeStats()
+	if len(typeStats) != 2 {
+		t.Fatalf("expected stats for every constraint type: %v", typeStats)
+	}
+	for _, s := range typeStats {
+		if s.Type == "propagator.largerThan" && s.Calls != stats[0].Calls+stats[1].Calls {
+			t.Fatalf("wrong type stats: %+v", s)
+		}
+	}
+
+	table := &bytes.Buffer{}
+	if err := profiler.WriteTypeTable(table); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(table.String(), "CONTRADICTIONS") || !strings.Contains(table.String(), "propagator.constraint") {
+		t.Fatalf("wrong table:\n%s", table.String())
+	}
+}
+
+func TestProfiler_Panic(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+
+	csp.AddConstraint(panicking{varA})
+
+	model := csp.Model()
+
+	profiler := NewProfiler(context.Background())
+
+	solver := NewSolver(WithSeed(0), ProfileConstraints(profiler))
+
+	if _, err := solver.TrySolve(model); err == nil {
+		t.Fatalf("expected constraint to panic")
+	}
+
+	if stats := profiler.Stats(); stats[0].Calls != 2 {
+		t.Fatalf("expected the panicking call to be recorded: %+v", stats[0])
+	}
+}
+
+func TestProfiler_Do(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+
+	csp.AddConstraint(largerThan{varA, varB})
+
+	model := csp.Model()
+
+	profiler := NewProfiler(context.Background())
+
+	var labels []string
+	solver := NewSolver(
+		WithSeed(0),
+		ProfileConstraints(profiler),
+		OnEvent(ConstraintPropagated, func(e Event) {
+			request, _ := pprof.Label(profiler.labelled[e.ConstraintId], "request")
+			constraint, _ := pprof.Label(profiler.labelled[e.ConstraintId], "constraint")
+			labels = append(labels, request+" "+constraint)
+		}),
+	)
+
+	pprof.Do(context.Background(), pprof.Labels("request", "1"), func(ctx context.Context) {
+		profiler.Do(ctx, func() { solver.Solve(model) })
+	})
+
+	if len(labels) == 0 || labels[0] != "1 propagator.largerThan" {
+		t.Fatalf("expected the constraint label on top of the labels of the context: %v", labels)
+	}
+	if profiler.ctx != context.Background() {
+		t.Fatalf("expected the context of the profiler to be restored")
+	}
+}
@@ -0,0 +1,28 @@
+package propagator
+
+// Propagation holds the mutations that were applied by Propagate.
+type Propagation struct {
+	mutator *Mutator
+}
+
+// Revert reverts all mutations applied during the propagation, returning the model to its previous state.
+func (p Propagation) Revert() {
+	p.mutator.revertAll()
+}
+
+// Propagate runs the constraints on all domains of the model until no more changes are made, without searching.
+// Afterwards the domains only hold the values that could not be ruled out by propagation alone.
+// It returns false if propagation led to a contradiction, meaning the model cannot be solved.
+// The changes remain applied to the model until Propagation.Revert is called.
+func Propagate(model Model) (Propagation, bool) {
+	evaluator := newEvaluator()
+	for _, domain := range model.Domains {
+		evaluator.enqueueDomain(model, domain)
+	}
+
+	mutator := newMutator()
+
+	success := evaluator.evaluate(model, mutator)
+
+	return Propagation{mutator}, success
+}
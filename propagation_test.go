@@ -0,0 +1,63 @@
+package propagator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPropagate(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+	varC := AddVariableFromValues(csp, "C", []int{1, 2, 3})
+
+	csp.AddConstraint(largerThan{varA, varB})
+	csp.AddConstraint(largerThan{varB, varC})
+
+	model := csp.Model()
+
+	propagation, success := Propagate(model)
+
+	if !success {
+		t.Fatalf("expected propagation to succeed")
+	}
+	if !varA.IsAssigned() || varA.GetAssignedValue() != 3 {
+		t.Fatalf("wrong values for A: %v", varA.AvailableValues())
+	}
+	if !varB.IsAssigned() || varB.GetAssignedValue() != 2 {
+		t.Fatalf("wrong values for B: %v", varB.AvailableValues())
+	}
+	if !varC.IsAssigned() || varC.GetAssignedValue() != 1 {
+		t.Fatalf("wrong values for C: %v", varC.AvailableValues())
+	}
+
+	propagation.Revert()
+
+	if !slices.Equal(varA.AvailableValues(), []int{1, 2, 3}) || !slices.Equal(varC.AvailableValues(), []int{1, 2, 3}) {
+		t.Fatalf("model should be reverted")
+	}
+}
+
+func TestPropagate_Contradiction(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2})
+	varC := AddVariableFromValues(csp, "C", []int{1, 2})
+
+	csp.AddConstraint(largerThan{varA, varB})
+	csp.AddConstraint(largerThan{varB, varC})
+
+	model := csp.Model()
+
+	propagation, success := Propagate(model)
+
+	if success {
+		t.Fatalf("expected propagation to end in contradiction")
+	}
+
+	propagation.Revert()
+
+	if len(varA.AvailableValues()) != 2 || len(varB.AvailableValues()) != 2 || len(varC.AvailableValues()) != 2 {
+		t.Fatalf("model should be reverted")
+	}
+}
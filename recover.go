@@ -0,0 +1,76 @@
+package propagator
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// ConstraintPanicError describes a panic that occurred while propagating a constraint.
+type ConstraintPanicError struct {
+	// Constraint is the constraint that panicked.
+	Constraint ConstraintInfo
+	// Value is the value that was passed to panic.
+	Value any
+	// Stack is the stack trace of the panic.
+	Stack []byte
+}
+
+func (e *ConstraintPanicError) Error() string {
+	return fmt.Sprintf(
+		"constraint %d (%s) on domains %s panicked: %v",
+		e.Constraint.Id,
+		e.Constraint.Type,
+		strings.Join(e.Constraint.Domains, " "),
+		e.Value,
+	)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *ConstraintPanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// TrySolve runs the solving algorithm like Solve, but recovers when a constraint panics while propagating. All changes
+// to the model are then reverted, no further events are published and a *ConstraintPanicError is returned that
+// identifies the constraint. Panics outside of constraints are not recovered.
+func (s *Solver) TrySolve(model Model) (solved bool, err error) {
+	mark := len(s.trail)
+	s.evaluator.recoverPanics = true
+	defer func() {
+		s.evaluator.recoverPanics = false
+		r := recover()
+		if r == nil {
+			return
+		}
+		panicErr, ok := r.(*ConstraintPanicError)
+		if !ok {
+			panic(r)
+		}
+		s.revertTo(mark)
+		s.evaluator.reset()
+		solved, err = false, panicErr
+	}()
+
+	return s.Solve(model), nil
+}
+
+// recoverConstraintPanic is deferred around propagating a constraint while solving with TrySolve. It turns a panic into
+// a *ConstraintPanicError identifying the constraint and panics again with it.
+func recoverConstraintPanic(m Model, id constraintId) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if _, ok := r.(*ConstraintPanicError); ok {
+		panic(r)
+	}
+	panic(&ConstraintPanicError{
+		Constraint: m.describeConstraint(id),
+		Value:      r,
+		Stack:      debug.Stack(),
+	})
+}
@@ -0,0 +1,105 @@
+package propagator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSolver_TrySolve(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+
+	csp.AddConstraint(largerThan{varA, varB})
+	csp.AddConstraint(panicking{varB})
+
+	model := csp.Model()
+
+	solver := NewSolver(WithSeed(0))
+
+	solved, err := solver.TrySolve(model)
+	if solved {
+		t.Fatalf("expected solving to fail")
+	}
+
+	var panicErr *ConstraintPanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected constraint panic error, got %v", err)
+	}
+	if panicErr.Constraint.Id != 1 || panicErr.Constraint.Type != "propagator.panicking" || panicErr.Constraint.Domains[0] != "B" {
+		t.Fatalf("wrong constraint in error: %v", err)
+	}
+	if err.Error() != "constraint 1 (propagator.panicking) on domains B panicked: unassigned" {
+		t.Fatalf("wrong error message: %v", err)
+	}
+
+	if len(varA.AvailableValues()) != 3 || len(varB.AvailableValues()) != 3 {
+		t.Fatalf("expected all mutations to be reverted")
+	}
+	if len(solver.trail) != 0 || !solver.evaluator.queue.isEmpty() {
+		t.Fatalf("expected solver to be reset")
+	}
+}
+
+func TestSolver_TrySolve_StrictScopes(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2})
+
+	csp.AddConstraint(outOfScope{varA, varB})
+
+	model := csp.Model()
+
+	solver := NewSolver(StrictScopes())
+
+	_, err := solver.TrySolve(model)
+
+	var panicErr *ConstraintPanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected constraint panic error, got %v", err)
+	}
+	message, ok := panicErr.Value.(string)
+	if !ok || !strings.Contains(message, "propagator.outOfScope") || !strings.Contains(message, `"B"`) {
+		t.Fatalf("expected panic value naming constraint and domain, got %v", panicErr.Value)
+	}
+}
+
+func TestSolver_Solve_Panic(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+
+	csp.AddConstraint(largerThan{varA, varB})
+	csp.AddConstraint(panicking{varB})
+
+	model := csp.Model()
+
+	solver := NewSolver(WithSeed(0))
+	if _, err := solver.TrySolve(model); err == nil {
+		t.Fatalf("expected TrySolve to recover the panic")
+	}
+
+	defer func() {
+		if r := recover(); r != "unassigned" {
+			t.Fatalf("expected Solve to panic with the value of the constraint, got %v", r)
+		}
+	}()
+
+	solver.Solve(model)
+}
+
+// panicking panics as soon as its domain changes.
+type panicking struct {
+	v *Variable[int]
+}
+
+func (p panicking) Scope() []DomainId {
+	return IdsOf(p.v)
+}
+
+func (p panicking) Propagate(m *Mutator) {
+	if len(p.v.AvailableValues()) < 3 {
+		panic("unassigned")
+	}
+}
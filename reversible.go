@@ -0,0 +1,138 @@
+package propagator
+
+// reversible is state that can be restored to an earlier value by the Mutator that changed it.
+type reversible interface {
+	restore(old int)
+}
+
+// trailEntry stores the value a reversible had before it was changed, so it can later be restored.
+type trailEntry struct {
+	target reversible
+	old    int
+}
+
+// ReversibleInt is an int that is restored when the Mutator through which it was changed is reverted.
+// Constraints can use it to keep state between propagations that stays consistent when the solver backtracks.
+// Allocate it when defining the Problem, for instance as a field of the constraint.
+type ReversibleInt struct {
+	value int
+}
+
+// NewReversibleInt creates a new ReversibleInt holding the given value.
+func NewReversibleInt(value int) *ReversibleInt {
+	return &ReversibleInt{value: value}
+}
+
+// Value returns the current value.
+func (r *ReversibleInt) Value() int {
+	return r.value
+}
+
+// Set sets the value, recording the previous value on the mutator so it is restored when the mutator is reverted.
+func (r *ReversibleInt) Set(m *Mutator, value int) {
+	if r.value == value {
+		return
+	}
+	m.record(r, r.value)
+	r.value = value
+}
+
+func (r *ReversibleInt) restore(old int) {
+	r.value = old
+}
+
+// ReversibleBool is a bool that is restored when the Mutator through which it was changed is reverted.
+// Allocate it when defining the Problem, for instance as a field of the constraint.
+type ReversibleBool struct {
+	value bool
+}
+
+// NewReversibleBool creates a new ReversibleBool holding the given value.
+func NewReversibleBool(value bool) *ReversibleBool {
+	return &ReversibleBool{value: value}
+}
+
+// Value returns the current value.
+func (r *ReversibleBool) Value() bool {
+	return r.value
+}
+
+// Set sets the value, recording the previous value on the mutator so it is restored when the mutator is reverted.
+func (r *ReversibleBool) Set(m *Mutator, value bool) {
+	if r.value == value {
+		return
+	}
+	old := 0
+	if r.value {
+		old = 1
+	}
+	m.record(r, old)
+	r.value = value
+}
+
+func (r *ReversibleBool) restore(old int) {
+	r.value = old == 1
+}
+
+// ReversibleSparseSet is a set of the integers 0 to n-1 from which values can be removed. Removals are restored when
+// the Mutator through which they were made is reverted.
+// Allocate it when defining the Problem, for instance as a field of the constraint.
+type ReversibleSparseSet struct {
+	// values holds the values in the set up to size, followed by the removed values in reverse order of removal.
+	values []int
+	// positions holds the position of each value in values.
+	positions []int
+	size      int
+}
+
+// NewReversibleSparseSet creates a new ReversibleSparseSet holding the integers 0 to n-1.
+func NewReversibleSparseSet(n int) *ReversibleSparseSet {
+	set := &ReversibleSparseSet{
+		values:    make([]int, n),
+		positions: make([]int, n),
+		size:      n,
+	}
+	for i := 0; i < n; i++ {
+		set.values[i] = i
+		set.positions[i] = i
+	}
+	return set
+}
+
+// Size returns the number of values in the set.
+func (s *ReversibleSparseSet) Size() int {
+	return s.size
+}
+
+// Contains returns whether the value is in the set.
+func (s *ReversibleSparseSet) Contains(value int) bool {
+	if value < 0 || value >= len(s.positions) {
+		return false
+	}
+	return s.positions[value] < s.size
+}
+
+// Values returns the values in the set in no particular order. The returned slice is only valid until the set is
+// changed.
+func (s *ReversibleSparseSet) Values() []int {
+	return s.values[:s.size]
+}
+
+// Remove removes the value from the set, recording the change on the mutator so it is restored when the mutator is
+// reverted.
+func (s *ReversibleSparseSet) Remove(m *Mutator, value int) {
+	if !s.Contains(value) {
+		return
+	}
+	m.record(s, s.size)
+
+	last := s.values[s.size-1]
+	position := s.positions[value]
+	s.values[position], s.values[s.size-1] = last, value
+	s.positions[last], s.positions[value] = position, s.size-1
+	s.size--
+}
+
+func (s *ReversibleSparseSet) restore(old int) {
+	s.size = old
+}
@@ -0,0 +1,93 @@
+package propagator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReversible(t *testing.T) {
+	number := NewReversibleInt(1)
+	flag := NewReversibleBool(false)
+	set := NewReversibleSparseSet(4)
+
+	mutator := newMutator()
+
+	number.Set(mutator, 2)
+	flag.Set(mutator, true)
+	set.Remove(mutator, 1)
+	mutator.apply()
+
+	number.Set(mutator, 3)
+	set.Remove(mutator, 3)
+	set.Remove(mutator, 0)
+	mutator.apply()
+
+	if number.Value() != 3 || !flag.Value() || set.Size() != 1 || !set.Contains(2) {
+		t.Fatalf("wrong values after setting: %d %t %v", number.Value(), flag.Value(), set.Values())
+	}
+
+	mutator.revertPrevious()
+
+	values := slices.Clone(set.Values())
+	slices.Sort(values)
+	if number.Value() != 2 || !flag.Value() || !slices.Equal(values, []int{0, 2, 3}) {
+		t.Fatalf("wrong values after reverting previous: %d %t %v", number.Value(), flag.Value(), values)
+	}
+
+	mutator.revertAll()
+
+	if number.Value() != 1 || flag.Value() || set.Size() != 4 {
+		t.Fatalf("wrong values after reverting all: %d %t %v", number.Value(), flag.Value(), set.Values())
+	}
+}
+
+func TestReversible_Backtracking(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{0, 1, 2})
+	varB := AddVariableFromValues(csp, "B", []int{0, 1, 2})
+	varC := AddVariableFromValues(csp, "C", []int{0, 1, 2})
+
+	csp.AddConstraint(distinct{[]*Variable[int]{varA, varB, varC}, NewReversibleSparseSet(3)})
+
+	model := csp.Model()
+
+	var solutions [][3]int
+
+	solver := NewSolver(
+		WithSeed(0),
+		FindAllSolutions(),
+		On(SolutionFound, func(m Model) {
+			solutions = append(solutions, [3]int{varA.GetAssignedValue(), varB.GetAssignedValue(), varC.GetAssignedValue()})
+		}),
+	)
+
+	solver.Solve(model)
+
+	if len(solutions) != 6 {
+		t.Fatalf("expected 6 solutions, got %v", solutions)
+	}
+}
+
+// distinct is an all different constraint that tracks the values that are not yet taken.
+type distinct struct {
+	vars []*Variable[int]
+	free *ReversibleSparseSet
+}
+
+func (d distinct) Scope() []DomainId {
+	return IdsOf(d.vars...)
+}
+
+func (d distinct) Propagate(m *Mutator) {
+	for _, v := range d.vars {
+		if !v.IsAssigned() || !d.free.Contains(v.GetAssignedValue()) {
+			continue
+		}
+		d.free.Remove(m, v.GetAssignedValue())
+		for _, other := range d.vars {
+			if other != v {
+				m.Add(other.ExcludeByValue(v.GetAssignedValue()))
+			}
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package propagator
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"math/rand"
+	"sort"
+)
+
+// defaultCellSize is the number of solutions a Sampler enumerates at most to draw a sample from.
+const defaultCellSize = 32
+
+// Sampler draws a single solution from all solutions of a model.
+// Unlike the randomized pickers of the Solver, which favour solutions that are found early in the search, the Sampler
+// returns every solution with (close to) equal chance or, when weighted, with a chance (close to) proportional to the
+// product of the probabilities of the assigned indices.
+// Sampling is hashing-based: random XOR constraints on the binary encoding of the assigned indices split the solution
+// space into cells of about equal size. Constraints are added until a cell holds no more solutions than the cell size,
+// so only that cell has to be enumerated. Models with no more solutions than the cell size are sampled exactly. For
+// larger models, uniform samples are near-uniform and weighted samples are only approximately proportional, as the
+// cells and the weights within them vary.
+type Sampler struct {
+	rnd      *rand.Rand
+	weighted bool
+	cellSize int
+}
+
+// SamplerOption functional option for the Sampler.
+type SamplerOption func(sampler *Sampler)
+
+// NewSampler creates a new sampler. It allows for SamplerOptions to customize the sampler behavior.
+func NewSampler(options ...SamplerOption) Sampler {
+	sampler := Sampler{
+		rnd:      rand.New(rand.NewSource(int64(new(maphash.Hash).Sum64()))),
+		weighted: false,
+		cellSize: defaultCellSize,
+	}
+	for _, opt := range options {
+		opt(&sampler)
+	}
+	return sampler
+}
+
+// SampleWithSeed explicitly sets the random seed to allow reproducible sampling.
+func SampleWithSeed(seed int64) SamplerOption {
+	return func(s *Sampler) {
+		s.rnd = rand.New(rand.NewSource(seed))
+	}
+}
+
+// SampleUniformly gives every solution an equal chance of being sampled. This is the default.
+func SampleUniformly() SamplerOption {
+	return func(s *Sampler) {
+		s.weighted = false
+	}
+}
+
+// SampleWeighted gives every solution a chance of being sampled proportional to the product of the probabilities of
+// the indices assigned to its (non-hidden) domains.
+func SampleWeighted() SamplerOption {
+	return func(s *Sampler) {
+		s.weighted = true
+	}
+}
+
+// SampleCellSize sets the number of solutions that are enumerated at most to draw a sample from. Larger cells give
+// samples closer to uniform, at the cost of enumerating more solutions per sample. The default is 32.
+func SampleCellSize(size int) SamplerOption {
+	return func(s *Sampler) {
+		s.cellSize = max(size, 1)
+	}
+}
+
+// sampledSolution holds the assigned index of every domain in a solution, or -1 for hidden domains, and its weight.
+type sampledSolution struct {
+	indices []int
+	weight  float64
+}
+
+// Sample draws a solution from the Model and returns whether a solution could be found.
+// The model is updated to reflect the sampled solution.
+func (s *Sampler) Sample(model Model) bool {
+	var found []sampledSolution
+	solver := NewSolver(
+		WithSeed(s.rnd.Int63()),
+		FindNSolutions(s.cellSize+1),
+		SelectDomainsByIndex(),
+		SelectIndicesAtRandom(),
+		On(SolutionFound, func(m Model) {
+			found = append(found, s.record(m))
+		}),
+	)
+	enumerate := func(m Model) []sampledSolution {
+		found = nil
+		solver.isSolvable(m)
+		return found
+	}
+
+	solutions := enumerate(model)
+	if len(solutions) == 0 {
+		return false
+	}
+
+	domains := sampledDomains(model)
+	// cell enumerates a random cell for the given number of hashes.
+	cell := func(numHashes int) []sampledSolution {
+		return enumerate(model.withConstraints(s.hash(domains, numHashes)...))
+	}
+
+	// Models with more solutions than fit in a cell are split into cells by hashing.
+	if len(solutions) > s.cellSize {
+		numHashes := minHashes(func(numHashes int) bool { return len(cell(numHashes)) > s.cellSize })
+		for {
+			solutions = cell(numHashes)
+			if len(solutions) > s.cellSize {
+				numHashes++
+				continue
+			}
+			// Accepting a cell with a chance proportional to its size gives every solution the same chance of being
+			// sampled for a given number of hashes.
+			if s.rnd.Intn(s.cellSize) < len(solutions) {
+				break
+			}
+		}
+	}
+
+	mark := len(solver.trail)
+	assignments := solver.newMutator()
+	for id, index := range s.pick(solutions).indices {
+		if index == -1 {
+			continue
+		}
+		assignments.Add(model.Domains[id].Assign(index))
+	}
+	assignments.apply()
+
+	_, success := solver.propagate(model, model.Domains...)
+	if !success {
+		solver.revertTo(mark)
+		return false
+	}
+
+	return true
+}
+
+// minHashes returns the lowest number of hashes for which a cell is not too large. As every probe enumerates a cell,
+// it doubles the number of hashes until the cell is small enough and then bisects.
+func minHashes(isTooLarge func(numHashes int) bool) int {
+	low, high := 0, 1
+	for isTooLarge(high) {
+		low, high = high, high*2
+	}
+	for high-low > 1 {
+		middle := (low + high) / 2
+		if isTooLarge(middle) {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return high
+}
+
+// record returns the solution the model currently is in.
+func (s *Sampler) record(m Model) sampledSolution {
+	indices := make([]int, len(m.Domains))
+	for i, domain := range m.Domains {
+		if domain.IsHidden() {
+			indices[i] = -1
+			continue
+		}
+		indices[i] = domain.AvailableIndices()[0]
+	}
+	return sampledSolution{indices: indices, weight: s.weight(m)}
+}
+
+// weight returns the relative chance of sampling the solution the model currently is in.
+func (s *Sampler) weight(m Model) float64 {
+	if !s.weighted {
+		return 1.0
+	}
+
+	weight := 1.0
+	for _, domain := range m.Domains {
+		if domain.IsHidden() {
+			continue
+		}
+		weight *= domain.IndexProbability(domain.AvailableIndices()[0])
+	}
+	return weight
+}
+
+// pick draws one of the solutions with a chance proportional to its weight.
+func (s *Sampler) pick(solutions []sampledSolution) sampledSolution {
+	total := 0.0
+	for _, solution := range solutions {
+		total += solution.weight
+	}
+	r := s.rnd.Float64() * total
+	for _, solution := range solutions {
+		r -= solution.weight
+		if r < 0 {
+			return solution
+		}
+	}
+	return solutions[len(solutions)-1]
+}
+
+// sampledDomains returns the domains making up a solution, in the order in which they are picked by index.
+func sampledDomains(m Model) []*Domain {
+	domains := make([]*Domain, 0, len(m.Domains))
+	for _, domain := range m.Domains {
+		if !domain.IsHidden() {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// hash returns random XOR constraints on the bits of the indices of the domains, which together select a random cell
+// of the solution space. Every constraint has its own pivot bit, which is set only in that constraint, and no bits of
+// domains after the domain of its pivot. As the domains are searched in order, the constraints with pivots in a domain
+// then fix those bits as soon as the domain is reached, and the cell is enumerated without searching the solutions
+// outside of it. Pivots are taken from the last domains backwards, first from the bits that are below the highest bit
+// of every domain, so that fixing them never rules out all indices.
+// There are numHashes constraints, unless the indices have fewer bits.
+func (s *Sampler) hash(domains []*Domain, numHashes int) []Constraint {
+	offsets := make([]int, len(domains)+1)
+	for i, domain := range domains {
+		offsets[i+1] = offsets[i] + bits.Len(uint(domain.numIndices()-1))
+	}
+	numBits := offsets[len(domains)]
+
+	candidates := make([]int, 0, numBits)
+	var highBits []int
+	for i := len(domains) - 1; i >= 0; i-- {
+		numLowBits := bits.Len(uint(domains[i].numIndices())) - 1
+		for b := offsets[i]; b < offsets[i+1]; b++ {
+			if b-offsets[i] < numLowBits {
+				candidates = append(candidates, b)
+			} else {
+				highBits = append(highBits, b)
+			}
+		}
+	}
+	pivots := append(candidates, highBits...)[:min(numHashes, numBits)]
+
+	isPivot := newBitset(numBits)
+	for _, pivot := range pivots {
+		isPivot.set(pivot)
+	}
+
+	hashes := make([]Constraint, len(pivots))
+	for i, pivot := range pivots {
+		last := sort.SearchInts(offsets, pivot+1) - 1
+		row := newBitset(numBits)
+		row.set(pivot)
+		for b := 0; b < offsets[last+1]; b++ {
+			if !isPivot.has(b) && s.rnd.Intn(2) == 1 {
+				row.set(b)
+			}
+		}
+		hashes[i] = newXorConstraint(domains[:last+1], offsets, row, s.rnd.Intn(2) == 1)
+	}
+	return hashes
+}
+
+// xorConstraint requires the number of set bits in the indices of its domains, each masked by the mask of the domain,
+// to be odd if parity is set and even otherwise.
+type xorConstraint struct {
+	scope   []DomainId
+	domains []*Domain
+	masks   []uint
+	wakes   []Wake
+	parity  bool
+}
+
+// newXorConstraint creates the constraint for a row of bits, where the bits of domain i run from offsets[i] up to
+// offsets[i+1].
+func newXorConstraint(domains []*Domain, offsets []int, row bitset, parity bool) xorConstraint {
+	c := xorConstraint{parity: parity}
+	for i, domain := range domains {
+		mask := uint(0)
+		for b := offsets[i]; b < offsets[i+1]; b++ {
+			if row.has(b) {
+				mask |= 1 << (b - offsets[i])
+			}
+		}
+		if mask == 0 {
+			continue
+		}
+		c.scope = append(c.scope, domain.id)
+		c.domains = append(c.domains, domain)
+		c.masks = append(c.masks, mask)
+		c.wakes = append(c.wakes, WakeOnAssign)
+	}
+	return c
+}
+
+func (c xorConstraint) Scope() []DomainId {
+	return c.scope
+}
+
+func (c xorConstraint) Cost() PropagationCost {
+	return CostLinear
+}
+
+func (c xorConstraint) Watches() []Wake {
+	return c.wakes
+}
+
+// Propagate fails when all domains are assigned with the wrong parity, and when a single domain is left unassigned,
+// excludes its indices that would give the wrong parity.
+func (c xorConstraint) Propagate(m *Mutator) {
+	parity := c.parity
+	unassigned := -1
+	for i, domain := range c.domains {
+		if !domain.IsAssigned() {
+			if unassigned != -1 {
+				return
+			}
+			unassigned = i
+			continue
+		}
+		parity = parity != c.bit(i, domain.AvailableIndices()[0])
+	}
+
+	if unassigned == -1 {
+		if parity {
+			m.Fail("not in the sampled cell")
+		}
+		return
+	}
+
+	domain := c.domains[unassigned]
+	var excluded []int
+	for _, index := range domain.AvailableIndices() {
+		if c.bit(unassigned, index) != parity {
+			excluded = append(excluded, index)
+		}
+	}
+	if len(excluded) > 0 {
+		m.Add(domain.Exclude(excluded...))
+	}
+}
+
+// bit returns whether the masked index of the i-th domain has an odd number of set bits.
+func (c xorConstraint) bit(i int, index int) bool {
+	return bits.OnesCount(uint(index)&c.masks[i])%2 == 1
+}
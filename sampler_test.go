@@ -0,0 +1,126 @@
+package propagator
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSampler_Uniform(t *testing.T) {
+	sampler := NewSampler(SampleWithSeed(0))
+
+	counts := make(map[[2]int]int)
+	for i := 0; i < 3000; i++ {
+		csp := NewProblem()
+		varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+		varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+
+		csp.AddConstraint(largerThan{varA, varB})
+
+		model := csp.Model()
+
+		if !sampler.Sample(model) {
+			t.Fatalf("failed to sample a solution [RUN=%d]", i)
+		}
+
+		counts[[2]int{varA.GetAssignedValue(), varB.GetAssignedValue()}]++
+	}
+
+	if len(counts) != 3 {
+		t.Fatalf("wrong or missing solutions: %v", counts)
+	}
+	for solution, count := range counts {
+		if count < 900 || count > 1100 {
+			t.Fatalf("solution %v is not sampled uniformly: %v", solution, counts)
+		}
+	}
+}
+
+func TestSampler_Weighted(t *testing.T) {
+	sampler := NewSampler(SampleWithSeed(0), SampleWeighted())
+
+	counts := make(map[int]int)
+	for i := 0; i < 4000; i++ {
+		csp := NewProblem()
+		varA := AddVariable(csp, "A", []DomainValue[int]{{0, 1.0, 1}, {0, 3.0, 2}})
+
+		model := csp.Model()
+
+		if !sampler.Sample(model) {
+			t.Fatalf("failed to sample a solution [RUN=%d]", i)
+		}
+
+		counts[varA.GetAssignedValue()]++
+	}
+
+	if counts[1] < 850 || counts[1] > 1150 || counts[2] < 2850 || counts[2] > 3150 {
+		t.Fatalf("solutions are not sampled proportional to their probability: %v", counts)
+	}
+}
+
+func TestSampler_Unsolvable(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1})
+	varB := AddVariableFromValues(csp, "B", []int{1})
+
+	csp.AddConstraint(largerThan{varA, varB})
+
+	model := csp.Model()
+
+	sampler := NewSampler(SampleWithSeed(0))
+
+	if sampler.Sample(model) {
+		t.Fatalf("expected no solution to be sampled")
+	}
+}
+
+func TestSampler_Hashing(t *testing.T) {
+	sampler := NewSampler(SampleWithSeed(0), SampleCellSize(16))
+
+	counts := make(map[[2]int]int)
+	for i := 0; i < 3000; i++ {
+		csp := NewProblem()
+		varA := AddVariableFromValues(csp, "A", []int{1, 2, 3, 4})
+		varB := AddVariableFromValues(csp, "B", []int{1, 2, 3, 4})
+		AddVariableFromValues(csp, "C", []int{1, 2, 3, 4})
+		AddVariableFromValues(csp, "D", []int{1, 2, 3, 4})
+		AddVariableFromValues(csp, "E", []int{1, 2, 3})
+
+		csp.AddConstraint(largerThan{varA, varB})
+
+		model := csp.Model()
+
+		if !sampler.Sample(model) {
+			t.Fatalf("failed to sample a solution [RUN=%d]", i)
+		}
+
+		counts[[2]int{varA.GetAssignedValue(), varB.GetAssignedValue()}]++
+	}
+
+	if len(counts) != 6 {
+		t.Fatalf("wrong or missing solutions: %v", counts)
+	}
+	for solution, count := range counts {
+		if count < 400 || count > 600 {
+			t.Fatalf("solution %v is not sampled uniformly: %v", solution, counts)
+		}
+	}
+}
+
+func TestSampler_LargeSolutionSpace(t *testing.T) {
+	csp := NewProblem()
+	variables := make([]*Variable[int], 30)
+	for i := range variables {
+		variables[i] = AddVariableFromValues(csp, fmt.Sprintf("V%d", i), []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+	}
+
+	model := csp.Model()
+
+	sampler := NewSampler(SampleWithSeed(0))
+
+	if !sampler.Sample(model) {
+		t.Fatalf("failed to sample a solution")
+	}
+	if !model.IsSolved() {
+		t.Fatalf("expected the model to be solved")
+	}
+}
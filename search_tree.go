@@ -0,0 +1,123 @@
+package propagator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// ExportSearchTree writes the explored search tree to w in Graphviz DOT format when the solver is finished.
+// Nodes are labelled with the domain chosen by the domain picker and edges with the tried value. Leaves where
+// propagation failed are marked with the domain that was wiped out, the constraint that caused it and the reason it
+// gave, and leaves that are solutions are highlighted.
+// At most maxNodes nodes are written; a value of 0 or less writes the full tree.
+func ExportSearchTree(w io.Writer, maxNodes int) SolverOption {
+	return func(s *Solver) {
+		tree := &searchTree{maxNodes: maxNodes}
+
+		s.events.Subscribe(Start, func(e Event) { tree.reset() })
+		s.events.Subscribe(Assign, tree.assign)
+		s.events.Subscribe(Backtrack, tree.backtrack)
+		s.events.Subscribe(Contradiction, tree.contradiction)
+		s.events.Subscribe(SolutionFound, tree.solution)
+		s.events.Subscribe(Finished, func(e Event) { tree.write(w) })
+	}
+}
+
+// searchTree builds a search tree from solver events.
+type searchTree struct {
+	maxNodes int
+	nodes    []searchNode
+	// path holds the nodes from the root to the current node, or -1 for nodes that were omitted.
+	path    []int
+	omitted int
+}
+
+// searchNode is a single node in the search tree.
+type searchNode struct {
+	parent   int
+	value    string
+	domain   string
+	failure  string
+	solution int
+}
+
+func (t *searchTree) reset() {
+	t.nodes = []searchNode{{parent: -1}}
+	t.path = []int{0}
+	t.omitted = 0
+}
+
+func (t *searchTree) assign(e Event) {
+	t.path = t.path[:e.Depth]
+	parent := t.path[e.Depth-1]
+
+	if parent == -1 || (t.maxNodes > 0 && len(t.nodes) >= t.maxNodes) {
+		t.omitted++
+		t.path = append(t.path, -1)
+		return
+	}
+
+	if t.nodes[parent].domain == "" {
+		t.nodes[parent].domain = e.Domain.Name()
+	}
+	t.nodes = append(t.nodes, searchNode{parent: parent, value: fmt.Sprint(e.Domain.indexValue(e.Index))})
+	t.path = append(t.path, len(t.nodes)-1)
+}
+
+func (t *searchTree) backtrack(e Event) {
+	t.path = t.path[:e.Depth]
+}
+
+func (t *searchTree) contradiction(e Event) {
+	node := t.path[len(t.path)-1]
+	if node == -1 {
+		return
+	}
+	failure := "contradiction"
+	if e.Domain != nil {
+		failure = fmt.Sprintf("%s wiped out", e.Domain.Name())
+	}
+	if e.ConstraintId != -1 {
+		failure = fmt.Sprintf("%s by %s (%d)", failure, e.Model.describeConstraint(e.ConstraintId).Type, e.ConstraintId)
+	}
+	if e.Reason != "" {
+		failure = fmt.Sprintf("%s: %s", failure, e.Reason)
+	}
+	t.nodes[node].failure = failure
+}
+
+func (t *searchTree) solution(e Event) {
+	node := t.path[len(t.path)-1]
+	if node == -1 {
+		return
+	}
+	t.nodes[node].solution = e.Solution
+}
+
+func (t *searchTree) write(w io.Writer) {
+	buf := bufio.NewWriter(w)
+
+	fmt.Fprintf(buf, "digraph search {\n")
+	for id, node := range t.nodes {
+		switch {
+		case node.solution > 0:
+			fmt.Fprintf(buf, "\tn%d [label=%s, shape=doublecircle, style=filled, fillcolor=palegreen];\n", id, quoteDOT(fmt.Sprintf("solution %d", node.solution)))
+		case node.failure != "":
+			fmt.Fprintf(buf, "\tn%d [label=%s, shape=box, color=red];\n", id, quoteDOT(node.failure))
+		case node.domain != "":
+			fmt.Fprintf(buf, "\tn%d [label=%s];\n", id, quoteDOT(node.domain))
+		default:
+			fmt.Fprintf(buf, "\tn%d [label=\"\", shape=point];\n", id)
+		}
+		if node.parent != -1 {
+			fmt.Fprintf(buf, "\tn%d -> n%d [label=%s];\n", node.parent, id, quoteDOT(node.value))
+		}
+	}
+	if t.omitted > 0 {
+		fmt.Fprintf(buf, "\tomitted [label=\"%d more nodes omitted\", shape=plaintext];\n", t.omitted)
+	}
+	fmt.Fprintf(buf, "}\n")
+
+	buf.Flush()
+}
@@ -0,0 +1,71 @@
+package propagator
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExportSearchTree(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariable(csp, "Å", []DomainValue[int]{{0, 1.0, 5}, {1, 1.0, 1}})
+	varB := AddVariable(csp, "B", []DomainValue[int]{{0, 1.0, 1}})
+
+	csp.AddConstraint(constraint{varA, varB})
+
+	model := csp.Model()
+
+	dot := &bytes.Buffer{}
+
+	solver := NewSolver(
+		WithSeed(0),
+		ExportSearchTree(dot, 0),
+	)
+
+	if !solver.Solve(model) {
+		t.Fatalf("failed to find solution")
+	}
+
+	expected := `digraph search {
+	n0 [label="Å"];
+	n1 [label="Å wiped out by propagator.constraint (0)", shape=box, color=red];
+	n0 -> n1 [label="5"];
+	n2 [label="solution 1", shape=doublecircle, style=filled, fillcolor=palegreen];
+	n0 -> n2 [label="1"];
+}
+`
+	if dot.String() != expected {
+		t.Fatalf("wrong search tree:\n%s", dot.String())
+	}
+}
+
+func TestExportSearchTree_MaxNodes(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariable(csp, "A", []DomainValue[int]{{0, 1.0, 0}, {1, 1.0, 1}})
+	varB := AddVariable(csp, "B", []DomainValue[int]{{0, 1.0, 1}})
+
+	csp.AddConstraint(constraint{varA, varB})
+
+	model := csp.Model()
+
+	dot := &bytes.Buffer{}
+
+	solver := NewSolver(
+		WithSeed(0),
+		ExportSearchTree(dot, 2),
+	)
+
+	if !solver.Solve(model) {
+		t.Fatalf("failed to find solution")
+	}
+
+	expected := `digraph search {
+	n0 [label="A"];
+	n1 [label="A wiped out by propagator.constraint (0)", shape=box, color=red];
+	n0 -> n1 [label="0"];
+	omitted [label="1 more nodes omitted", shape=plaintext];
+}
+`
+	if dot.String() != expected {
+		t.Fatalf("wrong search tree:\n%s", dot.String())
+	}
+}
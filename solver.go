@@ -8,39 +8,77 @@ import (
 
 // Solver is responsible for solving a given model.
 type Solver struct {
+	// seed is the seed of rnd, from which solvers used internally derive their own randomness.
+	seed           int64
 	rnd            *rand.Rand
 	domainPicker   domainPicker
 	indexPicker    indexPicker
 	maxSolutions   int
 	solutionsFound int
 
-	queue  *ds.SetQueue[*Domain]
-	events *ds.EventBus[Model]
+	evaluator *evaluator
+	events    *ds.EventBus[Event]
+
+	// trail holds the mutators that are currently applied to the model, in the order they were created.
+	trail []*Mutator
+	// free holds released mutators, which are reused so their buffers do not have to be allocated again.
+	free []*Mutator
 }
 
 // SolverEvent is used as key to hook functions to the solver.
 type SolverEvent = string
 
 const (
-	Start          SolverEvent = "Start"
-	Finished       SolverEvent = "Finished"
-	SolutionFound  SolverEvent = "SolutionFound"
-	Failure        SolverEvent = "Failure"
-	SearchStart    SolverEvent = "SearchStart"
-	PropagateStart SolverEvent = "PropagateStart"
-	Select         SolverEvent = "Select"
+	Start                SolverEvent = "Start"
+	Finished             SolverEvent = "Finished"
+	SolutionFound        SolverEvent = "SolutionFound"
+	Failure              SolverEvent = "Failure"
+	SearchStart          SolverEvent = "SearchStart"
+	PropagateStart       SolverEvent = "PropagateStart"
+	ConstraintPropagated SolverEvent = "ConstraintPropagated"
+	Contradiction        SolverEvent = "Contradiction"
+	Select               SolverEvent = "Select"
+	Assign               SolverEvent = "Assign"
+	Backtrack            SolverEvent = "Backtrack"
 )
 
+// Event holds the details of a SolverEvent.
+type Event struct {
+	// Model is the model being solved.
+	Model Model
+	// Depth is the number of decisions that are applied.
+	Depth int
+	// Domain is the domain the event is about: the decided or backtracked domain for Assign and Backtrack, the decided
+	// domain for PropagateStart and the domain that was wiped out for Contradiction. It is nil for events not related
+	// to a specific domain.
+	Domain *Domain
+	// Index is the index that was assigned for Assign or excluded for Backtrack, or -1 for other events.
+	Index int
+	// ConstraintId is the id of the constraint that was propagated for ConstraintPropagated or that caused a
+	// Contradiction, or -1 if it is not known or not relevant. For Failure it is the constraint that caused the last
+	// contradiction.
+	ConstraintId int
+	// Reason is the reason given to Mutator.Fail by the constraint that caused a Contradiction, or of the last
+	// contradiction for Failure. It is empty if the contradiction was caused by wiping out a domain.
+	Reason string
+	// Solution is the number of solutions found so far, which for SolutionFound is the number of the found solution.
+	Solution int
+}
+
 // NewSolver creates a new solver. It allows for SolverOptions to customize the solver behavior.
 func NewSolver(options ...SolverOption) Solver {
+	seed := int64(new(maphash.Hash).Sum64())
 	solver := Solver{
-		rnd:            rand.New(rand.NewSource(int64(new(maphash.Hash).Sum64()))),
+		seed:           seed,
+		rnd:            rand.New(rand.NewSource(seed)),
 		domainPicker:   &MinRemainingValuesPicker{},
 		indexPicker:    &ProbabilisticIndexPicker{},
 		solutionsFound: 0,
 		maxSolutions:   1,
-		events:         ds.NewEventBus[Model](),
-		queue:          ds.NewSetQueue[*Domain](), // domain ids
+		events:         ds.NewEventBus[Event](),
+		evaluator:      newEvaluator(),
+		trail:          []*Mutator{},
+		free:           []*Mutator{},
 	}
 	for _, opt := range options {
 		opt(&solver)
@@ -51,119 +89,67 @@ func NewSolver(options ...SolverOption) Solver {
 // Solve runs the solving algorithm on the Model and returns whether a solution could be found.
 // The model is updated to reflect the found solution.
 func (s *Solver) Solve(model Model) bool {
-	s.events.Publish(Start, model)
-
-	s.domainPicker.init(model, s.rnd)
-	s.indexPicker.init(model, s.rnd)
-
-	mutations, success := s.propagate(model, model.Domains...)
-	if success {
-		s.events.Publish(SearchStart, model)
-		s.selectNext(0, model)
-	}
-
-	hasSolutions := s.solutionsFound > 0
-	if !hasSolutions {
-		s.events.Publish(Failure, model)
-		mutations.revertAll()
+	stepper := NewStepper(s, model)
+	for stepper.Step() != StepDone {
 	}
-
-	s.events.Publish(Finished, model)
-	return hasSolutions
+	return stepper.IsSolved()
 }
 
-func (s *Solver) selectNext(level int, model Model) bool {
-	s.events.Publish(Select, model)
+func (s *Solver) propagate(model Model, domains ...*Domain) (*Mutator, bool) {
+	mutator := s.startPropagation(model, domains...)
 
-	if model.IsSolved() {
-		s.solutionsFound++
-		s.events.Publish(SolutionFound, model)
-		if s.maxSolutions > 0 && (s.maxSolutions == s.solutionsFound) {
-			return true
-		}
-	}
+	success := s.evaluator.evaluate(model, mutator)
 
-	domain := s.domainPicker.nextDomain(model)
+	return mutator, success
+}
 
-	if domain == nil {
-		return false
+// startPropagation queues the constraints of the given domains for propagation and returns the Mutator that collects
+// the changes.
+func (s *Solver) startPropagation(model Model, domains ...*Domain) *Mutator {
+	for _, domain := range domains {
+		s.evaluator.enqueueDomain(model, domain)
 	}
 
-	selectMutations := newMutator()
-
-	for {
-		selectedIndex := s.indexPicker.nextIndex(domain)
-		if selectedIndex == -1 {
-			selectMutations.revertAll()
-			return false
-		}
-
-		selectMutations.Add(domain.Assign(selectedIndex))
-		selectMutations.apply()
+	return s.newMutator()
+}
 
-		propagateMutations, success := s.propagate(model, domain)
+// startDecisionPropagation queues the constraints of the decided domain for propagation, passing the last applied
+// changes of the decision Mutator to incremental constraints, and returns the Mutator that collects the changes.
+func (s *Solver) startDecisionPropagation(model Model, domain *Domain, decision *Mutator) *Mutator {
+	s.evaluator.enqueueDecision(model, domain, decision)
 
-		if success && s.selectNext(level+1, model) {
-			return true
-		}
+	return s.newMutator()
+}
 
-		propagateMutations.revertAll()
-		selectMutations.revertPrevious()
-		selectMutations.Add(domain.Exclude(selectedIndex))
-		selectMutations.apply()
+// newMutator creates a new Mutator, or reuses a released one, and places it on the trail, so it can be reverted when
+// the solver is done.
+func (s *Solver) newMutator() *Mutator {
+	var mutator *Mutator
+	if len(s.free) > 0 {
+		mutator = s.free[len(s.free)-1]
+		s.free = s.free[:len(s.free)-1]
+	} else {
+		mutator = newMutator()
 	}
+	s.trail = append(s.trail, mutator)
+	return mutator
 }
 
-func (s *Solver) propagate(model Model, domains ...*Domain) (*Mutator, bool) {
-	s.events.Publish(PropagateStart, model)
-	for _, domain := range domains {
-		s.queue.Enqueue(domain)
+// release reverts the given Mutator, which must be the last one on the trail, and removes it from the trail. The
+// Mutator must not be used anymore, as it is reused by newMutator.
+func (s *Solver) release(mutator *Mutator) {
+	if s.trail[len(s.trail)-1] != mutator {
+		panic("mutators must be released in reverse order of creation")
 	}
-
-	mutator := newMutator()
-
-	success := evaluate(model, s.queue, mutator)
-
-	return mutator, success
+	mutator.reset()
+	s.trail = s.trail[:len(s.trail)-1]
+	s.free = append(s.free, mutator)
+	s.evaluator.untrackAll()
 }
 
-// TODO: this has been separated for use in LeastConstrainingValueIndexPicker
-func evaluate(m Model, queue *ds.SetQueue[*Domain], mutator *Mutator) bool {
-	for {
-		selectedDomain, hasNext := queue.Dequeue()
-		if !hasNext {
-			return true
-		}
-
-		targetDomains := ds.NewSet[*Domain]()
-
-		for _, constraintId := range m.domainConstraints[selectedDomain.id] {
-			constraint := m.constraints[constraintId]
-
-			mutator.setActiveConstraintId(constraintId)
-			constraint.constraint.Propagate(mutator)
-
-			for _, targetDomainId := range constraint.linkedDomains {
-				targetDomains.Insert(m.Domains[targetDomainId])
-			}
-		}
-
-		versions := make(map[DomainId]int)
-		for targetDomain := range targetDomains {
-			versions[targetDomain.id] = targetDomain.version()
-		}
-
-		mutator.apply()
-
-		for targetDomain := range targetDomains {
-			if targetDomain.IsInContradiction() {
-				queue.Reset()
-				return false
-			}
-
-			if targetDomain.version() > versions[targetDomain.id] {
-				queue.Enqueue(targetDomain)
-			}
-		}
+// revertTo reverts and releases all mutators that were placed on the trail after it had the given length.
+func (s *Solver) revertTo(mark int) {
+	for len(s.trail) > mark {
+		s.release(s.trail[len(s.trail)-1])
 	}
 }
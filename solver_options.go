@@ -3,22 +3,29 @@ package propagator
 import (
 	"log"
 	"math/rand"
-	"reflect"
-	"strings"
 )
 
 // SolverOption functional option for the Solver.
 type SolverOption func(solver *Solver)
 
-// WithSeed explicitly sets the random seed to allow reproducible randomness.
+// WithSeed explicitly sets the random seed to allow reproducible randomness. As constraints are always propagated in
+// the same order, solving the same model with the same seed yields the same search trace and solutions.
 func WithSeed(int int64) SolverOption {
 	return func(s *Solver) {
+		s.seed = int
 		s.rnd = rand.New(rand.NewSource(int))
 	}
 }
 
 // On hooks a function to the solver when the given SolverEvent fires.
 func On(event SolverEvent, f func(m Model)) SolverOption {
+	return func(s *Solver) {
+		s.events.Subscribe(event, func(e Event) { f(e.Model) })
+	}
+}
+
+// OnEvent hooks a function to the solver when the given SolverEvent fires, passing the full event details.
+func OnEvent(event SolverEvent, f func(e Event)) SolverOption {
 	return func(s *Solver) {
 		s.events.Subscribe(event, f)
 	}
@@ -28,14 +35,14 @@ func On(event SolverEvent, f func(m Model)) SolverOption {
 func LogInfo() SolverOption {
 	return func(s *Solver) {
 		round := 0
-		s.events.Subscribe(Start, func(m Model) { log.Printf("[SOLVER] Starting\n") })
-		s.events.Subscribe(Failure, func(m Model) { log.Print("[SOLVER] Failed finding solution\n") })
-		s.events.Subscribe(SolutionFound, func(m Model) { log.Print("[SOLVER] Solution found\n") })
-		s.events.Subscribe(Select, func(m Model) {
+		s.events.Subscribe(Start, func(e Event) { log.Printf("[SOLVER] Starting\n") })
+		s.events.Subscribe(Failure, func(e Event) { log.Print("[SOLVER] Failed finding solution\n") })
+		s.events.Subscribe(SolutionFound, func(e Event) { log.Print("[SOLVER] Solution found\n") })
+		s.events.Subscribe(Select, func(e Event) {
 			log.Printf("[SOLVER] Next round (%d)\n", round)
 			round++
 		})
-		s.events.Subscribe(PropagateStart, func(m Model) {
+		s.events.Subscribe(PropagateStart, func(e Event) {
 			log.Printf("[SOLVER] Start propagating constraints\n")
 		})
 	}
@@ -44,20 +51,24 @@ func LogInfo() SolverOption {
 // LogConstraints logs the model constraints when solving is started.
 func LogConstraints(model Model) SolverOption {
 	return func(s *Solver) {
-		s.events.Subscribe(Start, func(m Model) {
+		s.events.Subscribe(Start, func(e Event) {
 			log.Printf("CONSTRAINTS:\n")
-			for i, boundConstraint := range model.constraints {
-				constraintName := reflect.TypeOf(boundConstraint.constraint)
-				var links []string
-				for _, domain := range boundConstraint.linkedDomains {
-					links = append(links, model.Domains[domain].Name())
-				}
-				log.Printf("%-4d %s\n     %s\n", i, constraintName, strings.Join(links, " "))
+			for i := range model.constraints {
+				log.Printf("%s\n", model.describeConstraint(i))
 			}
 		})
 	}
 }
 
+// StrictScopes makes the solver panic when a constraint adds a mutation for a domain that is not in its scope, naming
+// the constraint and the domain. Use TrySolve to get an error instead. The constraint is not propagated when such a
+// domain changes, so it can miss updates.
+func StrictScopes() SolverOption {
+	return func(s *Solver) {
+		s.evaluator.strictScopes = true
+	}
+}
+
 // FindNSolutions stops the solver after finding a maximum of n solutions.
 func FindNSolutions(n int) SolverOption {
 	return func(s *Solver) {
@@ -1,7 +1,9 @@
 package propagator
 
 import (
+	"fmt"
 	"math"
+	"slices"
 	"testing"
 )
 
@@ -138,6 +140,50 @@ func TestSolve_Hidden(t *testing.T) {
 	}
 }
 
+func TestSolver_EventPayloads(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariable(csp, "A", []DomainValue[int]{{0, 1.0, 0}, {1, 1.0, 1}})
+	varB := AddVariable(csp, "B", []DomainValue[int]{{0, 1.0, 0}, {1, 1.0, 1}})
+
+	csp.AddConstraint(constraint{varA, varB})
+
+	model := csp.Model()
+
+	var events []string
+	record := func(e Event) {
+		name := ""
+		if e.Domain != nil {
+			name = e.Domain.Name()
+		}
+		events = append(events, fmt.Sprintf("%s:%d:%d:%d", name, e.Index, e.Depth, e.ConstraintId))
+	}
+
+	solutions := 0
+	solver := NewSolver(
+		WithSeed(0),
+		SelectDomainsByIndex(),
+		OnEvent(Assign, record),
+		OnEvent(Backtrack, record),
+		OnEvent(Contradiction, record),
+		OnEvent(SolutionFound, func(e Event) { solutions = e.Solution }),
+	)
+
+	if !solver.Solve(model) {
+		t.Fatalf("failed to find solution")
+	}
+
+	expected := []string{
+		"A:0:1:-1", "B:0:2:-1", "A:-1:2:0", "B:0:2:-1", "B:1:2:-1", "A:-1:2:0", "B:1:2:-1", "A:0:1:-1",
+		"A:1:1:-1", "B:0:2:-1", "A:-1:2:0", "B:0:2:-1", "B:1:2:-1",
+	}
+	if !slices.Equal(events, expected) {
+		t.Fatalf("wrong events: %v", events)
+	}
+	if solutions != 1 {
+		t.Fatalf("wrong solution number: %d", solutions)
+	}
+}
+
 type largerThan struct {
 	a *Variable[int]
 	b *Variable[int]
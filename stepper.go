@@ -0,0 +1,276 @@
+package propagator
+
+// StepKind describes what the solver did in a single step.
+type StepKind int
+
+const (
+	// StepPropagate propagated a single constraint from the propagation queue.
+	StepPropagate StepKind = iota
+	// StepDecide assigned an index to the domain chosen by the domain picker.
+	StepDecide
+	// StepBacktrack reverted a decision and excluded its index, because it did not lead to a solution.
+	StepBacktrack
+	// StepSolution found a solution.
+	StepSolution
+	// StepDone finished solving, because the search space is exhausted or enough solutions have been found.
+	StepDone
+)
+
+// Stepper runs the solving algorithm one step at a time, allowing the model to be inspected in between steps.
+// Use NewStepper to create a new stepper.
+type Stepper struct {
+	solver *Solver
+	model  Model
+	state  stepperState
+	// mark is the length of the solver trail when solving started.
+	mark int
+	// levels holds the decisions that are currently applied.
+	levels []searchLevel
+	// propagation is the Mutator of the propagation in progress.
+	propagation *Mutator
+	// contradiction is the event of the last contradiction, which is reported again if solving fails.
+	contradiction Event
+
+	domain       *Domain
+	index        int
+	constraintId constraintId
+	solved       bool
+}
+
+// searchLevel holds the state of a single decision in the search.
+type searchLevel struct {
+	domain             *Domain
+	index              int
+	selectMutations    *Mutator
+	propagateMutations *Mutator
+}
+
+type stepperState int
+
+const (
+	stateStart stepperState = iota
+	statePropagate
+	stateSelect
+	stateChooseDomain
+	stateTryIndex
+	stateBacktrack
+	stateFinish
+	stateDone
+)
+
+// NewStepper creates a new Stepper running the given solver on the Model.
+// The solver should not be used for anything else until the stepper is done.
+func NewStepper(solver *Solver, model Model) *Stepper {
+	return &Stepper{
+		solver: solver,
+		model:  model,
+		state:  stateStart,
+		mark:   len(solver.trail),
+		levels: []searchLevel{},
+
+		contradiction: Event{Index: -1, ConstraintId: -1},
+
+		domain:       nil,
+		index:        -1,
+		constraintId: -1,
+	}
+}
+
+// Step advances the solver by a single step and returns what kind of step was taken.
+// Once StepDone is returned, all following calls return StepDone as well.
+func (s *Stepper) Step() StepKind {
+	for {
+		if kind, isStep := s.advance(); isStep {
+			return kind
+		}
+	}
+}
+
+// IsDone returns whether the solver is done.
+func (s *Stepper) IsDone() bool {
+	return s.state == stateDone
+}
+
+// IsSolved returns whether the solver is done and has found at least one solution.
+func (s *Stepper) IsSolved() bool {
+	return s.solved
+}
+
+// Depth returns the number of decisions that are currently applied.
+func (s *Stepper) Depth() int {
+	return len(s.levels)
+}
+
+// Domain returns the domain of the decision that was made or reverted in the last step, or nil if the last step was
+// not a decision or backtrack.
+func (s *Stepper) Domain() *Domain {
+	return s.domain
+}
+
+// Index returns the index that was assigned or excluded in the last step, or -1 if the last step was not a decision
+// or backtrack.
+func (s *Stepper) Index() int {
+	return s.index
+}
+
+// ConstraintId returns the id of the constraint that was propagated in the last step, or -1 if the last step did not
+// propagate a constraint.
+func (s *Stepper) ConstraintId() int {
+	return s.constraintId
+}
+
+// advance executes the current state and moves to the next. It returns whether this was a step visible to the caller.
+func (s *Stepper) advance() (StepKind, bool) {
+	solver := s.solver
+
+	switch s.state {
+	case stateStart:
+		solver.solutionsFound = 0
+		s.publish(Start, nil, -1)
+
+		solver.domainPicker.init(s.model, solver.rnd)
+		solver.indexPicker.init(s.model, solver.rnd)
+
+		s.publish(PropagateStart, nil, -1)
+		s.propagation = solver.startPropagation(s.model, s.model.Domains...)
+		s.state = statePropagate
+		return 0, false
+
+	case statePropagate:
+		if solver.evaluator.queue.isEmpty() {
+			if len(s.levels) == 0 {
+				s.publish(SearchStart, nil, -1)
+			}
+			s.state = stateSelect
+			return 0, false
+		}
+
+		constraintId, success := solver.evaluator.evaluateNext(s.model, s.propagation)
+		s.setStep(nil, -1)
+		s.constraintId = constraintId
+		event := s.newEvent(nil, -1)
+		event.ConstraintId = constraintId
+		solver.events.Publish(ConstraintPropagated, event)
+		if !success {
+			event := s.newEvent(s.propagation.contradiction, -1)
+			event.ConstraintId = s.propagation.contradictionConstraintId
+			event.Reason = s.propagation.contradictionReason
+			s.contradiction = event
+			solver.events.Publish(Contradiction, event)
+			s.state = stateBacktrack
+		}
+		return StepPropagate, true
+
+	case stateSelect:
+		s.publish(Select, nil, -1)
+		s.state = stateChooseDomain
+
+		if s.model.IsSolved() {
+			solver.solutionsFound++
+			s.publish(SolutionFound, nil, -1)
+			if solver.maxSolutions > 0 && (solver.maxSolutions == solver.solutionsFound) {
+				s.state = stateFinish
+			}
+			s.setStep(nil, -1)
+			return StepSolution, true
+		}
+		return 0, false
+
+	case stateChooseDomain:
+		domain := solver.domainPicker.nextDomain(s.model)
+		if domain == nil {
+			s.state = stateBacktrack
+			return 0, false
+		}
+
+		s.levels = append(s.levels, searchLevel{
+			domain:          domain,
+			index:           -1,
+			selectMutations: solver.newMutator(),
+		})
+		s.state = stateTryIndex
+		return 0, false
+
+	case stateTryIndex:
+		level := &s.levels[len(s.levels)-1]
+
+		selectedIndex := solver.indexPicker.nextIndex(level.domain)
+		if selectedIndex == -1 {
+			solver.release(level.selectMutations)
+			s.levels = s.levels[:len(s.levels)-1]
+			s.state = stateBacktrack
+			return 0, false
+		}
+
+		level.index = selectedIndex
+		level.selectMutations.Add(level.domain.Assign(selectedIndex))
+		level.selectMutations.apply()
+
+		s.setStep(level.domain, selectedIndex)
+		s.publish(Assign, level.domain, selectedIndex)
+
+		s.publish(PropagateStart, level.domain, -1)
+		level.propagateMutations = solver.startDecisionPropagation(s.model, level.domain, level.selectMutations)
+		s.propagation = level.propagateMutations
+		s.state = statePropagate
+		return StepDecide, true
+
+	case stateBacktrack:
+		if len(s.levels) == 0 {
+			s.state = stateFinish
+			return 0, false
+		}
+
+		level := &s.levels[len(s.levels)-1]
+
+		solver.release(level.propagateMutations)
+		level.selectMutations.revertPrevious()
+		level.selectMutations.Add(level.domain.Exclude(level.index))
+		level.selectMutations.apply()
+
+		s.setStep(level.domain, level.index)
+		s.publish(Backtrack, level.domain, level.index)
+		s.state = stateTryIndex
+		return StepBacktrack, true
+
+	case stateFinish:
+		s.solved = solver.solutionsFound > 0
+		if !s.solved {
+			event := s.newEvent(nil, -1)
+			event.ConstraintId = s.contradiction.ConstraintId
+			event.Reason = s.contradiction.Reason
+			solver.events.Publish(Failure, event)
+			solver.revertTo(s.mark)
+		}
+
+		s.publish(Finished, nil, -1)
+		s.setStep(nil, -1)
+		s.state = stateDone
+		return StepDone, true
+
+	default:
+		return StepDone, true
+	}
+}
+
+// publish publishes the event for the given domain and index.
+func (s *Stepper) publish(event SolverEvent, domain *Domain, index int) {
+	s.solver.events.Publish(event, s.newEvent(domain, index))
+}
+
+func (s *Stepper) newEvent(domain *Domain, index int) Event {
+	return Event{
+		Model:        s.model,
+		Depth:        len(s.levels),
+		Domain:       domain,
+		Index:        index,
+		ConstraintId: -1,
+		Solution:     s.solver.solutionsFound,
+	}
+}
+
+func (s *Stepper) setStep(domain *Domain, index int) {
+	s.domain = domain
+	s.index = index
+	s.constraintId = -1
+}
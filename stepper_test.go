@@ -0,0 +1,74 @@
+package propagator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStepper(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2, 3})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2, 3})
+
+	csp.AddConstraint(largerThan{varA, varB})
+
+	model := csp.Model()
+
+	solver := NewSolver(WithSeed(0))
+	stepper := NewStepper(&solver, model)
+
+	counts := make(map[StepKind]int)
+	for !stepper.IsDone() {
+		kind := stepper.Step()
+		counts[kind]++
+
+		if kind == StepDecide {
+			if stepper.Domain() == nil || stepper.Index() == -1 || stepper.Depth() == 0 {
+				t.Fatalf("decision step should expose domain, index and depth")
+			}
+			if !stepper.Domain().IsAssigned() {
+				t.Fatalf("decided domain should be assigned")
+			}
+		}
+	}
+
+	if counts[StepPropagate] == 0 || counts[StepDecide] == 0 || counts[StepSolution] != 1 || counts[StepDone] != 1 {
+		t.Fatalf("unexpected steps: %v", counts)
+	}
+	if stepper.Step() != StepDone {
+		t.Fatalf("stepping a finished stepper should return StepDone")
+	}
+	if !stepper.IsSolved() {
+		t.Fatalf("expected a solution")
+	}
+	if varA.GetAssignedValue() != 3 || varB.GetAssignedValue() != 1 {
+		t.Fatalf("wrong solution: %d %d", varA.GetAssignedValue(), varB.GetAssignedValue())
+	}
+}
+
+func TestStepper_Backtrack(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariable(csp, "A", []DomainValue[int]{{0, 1.0, 0}, {1, 1.0, 1}})
+	varB := AddVariable(csp, "B", []DomainValue[int]{{0, 1.0, 0}, {1, 1.0, 1}})
+
+	csp.AddConstraint(constraint{varA, varB})
+
+	model := csp.Model()
+
+	solver := NewSolver(WithSeed(0), SelectDomainsByIndex())
+	stepper := NewStepper(&solver, model)
+
+	var backtracked []string
+	for !stepper.IsDone() {
+		if stepper.Step() == StepBacktrack {
+			backtracked = append(backtracked, stepper.Domain().Name())
+		}
+	}
+
+	if !stepper.IsSolved() || varA.GetAssignedValue() != 1 || varB.GetAssignedValue() != 1 {
+		t.Fatalf("expected solution with both variables set to 1")
+	}
+	if !slices.Equal(backtracked, []string{"B", "B", "A", "B"}) {
+		t.Fatalf("wrong backtracks: %v", backtracked)
+	}
+}
@@ -0,0 +1,79 @@
+package propagator
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// traceRecord is a single line of a JSON Lines search trace.
+type traceRecord struct {
+	Event          SolverEvent `json:"event"`
+	Depth          int         `json:"depth"`
+	Domain         string      `json:"domain,omitempty"`
+	DomainId       *DomainId   `json:"domain_id,omitempty"`
+	Index          *int        `json:"index,omitempty"`
+	ConstraintId   *int        `json:"constraint_id,omitempty"`
+	ConstraintType string      `json:"constraint_type,omitempty"`
+	Reason         string      `json:"reason,omitempty"`
+	Solution       int         `json:"solution,omitempty"`
+}
+
+// tracedEvents are the events written to a search trace.
+var tracedEvents = []SolverEvent{
+	Start,
+	Assign,
+	ConstraintPropagated,
+	Contradiction,
+	Backtrack,
+	SolutionFound,
+	Failure,
+	Finished,
+}
+
+// TraceJSON writes a trace of the search to w as JSON Lines: one JSON object per line for every decision, propagated
+// constraint, contradiction, backtrack and solution. Each record holds the event name and depth and, where relevant,
+// the domain name and id, the index, the id and type of the propagated constraint or the constraint causing a
+// contradiction, and the reason given for the contradiction.
+// Writing stops at the first write error.
+func TraceJSON(w io.Writer) SolverOption {
+	return func(s *Solver) {
+		encoder := json.NewEncoder(w)
+		var err error
+
+		for _, event := range tracedEvents {
+			event := event
+			s.events.Subscribe(event, func(e Event) {
+				if err != nil {
+					return
+				}
+				err = encoder.Encode(newTraceRecord(event, e))
+			})
+		}
+	}
+}
+
+func newTraceRecord(event SolverEvent, e Event) traceRecord {
+	record := traceRecord{
+		Event: event,
+		Depth: e.Depth,
+	}
+	if event == SolutionFound {
+		record.Solution = e.Solution
+	}
+	if e.Domain != nil {
+		id := e.Domain.id
+		record.Domain = e.Model.domainNames[id]
+		record.DomainId = &id
+	}
+	if e.Index != -1 {
+		index := e.Index
+		record.Index = &index
+	}
+	if e.ConstraintId != -1 {
+		constraintId := e.ConstraintId
+		record.ConstraintId = &constraintId
+		record.ConstraintType = e.Model.describeConstraint(constraintId).Type
+	}
+	record.Reason = e.Reason
+	return record
+}
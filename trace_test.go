@@ -0,0 +1,87 @@
+package propagator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTraceJSON(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariable(csp, "A", []DomainValue[int]{{0, 1.0, 0}, {1, 1.0, 1}})
+	varB := AddVariable(csp, "B", []DomainValue[int]{{0, 1.0, 1}})
+
+	csp.AddConstraint(constraint{varA, varB})
+
+	model := csp.Model()
+
+	trace := &bytes.Buffer{}
+
+	solver := NewSolver(
+		WithSeed(0),
+		TraceJSON(trace),
+	)
+
+	if !solver.Solve(model) {
+		t.Fatalf("failed to find solution")
+	}
+
+	expected := []string{
+		`{"event":"Start","depth":0}`,
+		`{"event":"ConstraintPropagated","depth":0,"constraint_id":0,"constraint_type":"propagator.constraint"}`,
+		`{"event":"Assign","depth":1,"domain":"A","domain_id":0,"index":0}`,
+		`{"event":"ConstraintPropagated","depth":1,"constraint_id":0,"constraint_type":"propagator.constraint"}`,
+		`{"event":"Contradiction","depth":1,"domain":"A","domain_id":0,"constraint_id":0,"constraint_type":"propagator.constraint"}`,
+		`{"event":"Backtrack","depth":1,"domain":"A","domain_id":0,"index":0}`,
+		`{"event":"Assign","depth":1,"domain":"A","domain_id":0,"index":1}`,
+		`{"event":"ConstraintPropagated","depth":1,"constraint_id":0,"constraint_type":"propagator.constraint"}`,
+		`{"event":"SolutionFound","depth":1,"solution":1}`,
+		`{"event":"Finished","depth":1}`,
+	}
+
+	lines := strings.Split(strings.TrimSpace(trace.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("wrong number of trace lines:\n%s", trace.String())
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Fatalf("wrong trace line %d:\n%s\nexpected:\n%s", i, line, expected[i])
+		}
+	}
+}
+
+func TestTraceJSON_Deterministic(t *testing.T) {
+	solve := func() string {
+		csp := NewProblem()
+		values := []DomainValue[int]{{0, 0.5, 1}, {0, 1.0, 2}, {1, 0.75, 3}, {0, 0.25, 4}, {0, 1.0, 5}}
+		variables := make([]*Variable[int], 0, len(values))
+		for i := range values {
+			variables = append(variables, AddVariable(csp, fmt.Sprintf("v%d", i), values))
+		}
+		csp.AddConstraint(distinct{variables, NewReversibleSparseSet(len(values) + 1)})
+		csp.AddConstraint(largerThan{variables[0], variables[1]})
+		csp.AddConstraint(largerThan{variables[2], variables[3]})
+		csp.AddConstraint(equals{variables[3], variables[4]})
+
+		model := csp.Model()
+
+		trace := &bytes.Buffer{}
+		solver := NewSolver(
+			WithSeed(7),
+			SelectDomainsAtRandom(),
+			FindNSolutions(3),
+			TraceJSON(trace),
+		)
+		solver.Solve(model)
+
+		return trace.String()
+	}
+
+	expected := solve()
+	for i := 0; i < 20; i++ {
+		if trace := solve(); trace != expected {
+			t.Fatalf("trace of run %d differs:\n%s\nexpected:\n%s", i, trace, expected)
+		}
+	}
+}
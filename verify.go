@@ -0,0 +1,45 @@
+package propagator
+
+import "fmt"
+
+// VerifySolutions checks every found solution against all constraints that implement Checker, and panics with the
+// constraint id, type and domain names if a solution violates one. It is meant for debugging constraints.
+func VerifySolutions() SolverOption {
+	return VerifySolutionsWith(func(e Event, violated ConstraintInfo) {
+		panic(fmt.Sprintf("solution %d violates constraint %s", e.Solution, violated))
+	})
+}
+
+// VerifySolutionsWith checks every found solution against all constraints that implement Checker, and calls report
+// for each violated constraint.
+func VerifySolutionsWith(report func(e Event, violated ConstraintInfo)) SolverOption {
+	return func(s *Solver) {
+		s.events.Subscribe(SolutionFound, func(e Event) {
+			for _, id := range e.Model.violatedConstraints() {
+				report(e, e.Model.describeConstraint(id))
+			}
+		})
+	}
+}
+
+// violatedConstraints returns the enabled constraints implementing Checker that have all domains in their scope
+// assigned and do not hold.
+func (m *Model) violatedConstraints() []constraintId {
+	var violated []constraintId
+iterateConstraints:
+	for id, boundConstraint := range m.constraints {
+		checker, ok := boundConstraint.constraint.(Checker)
+		if !ok || m.constraintDisabled[id] {
+			continue
+		}
+		for _, domain := range boundConstraint.linkedDomains {
+			if !m.Domains[domain].IsAssigned() {
+				continue iterateConstraints
+			}
+		}
+		if !checker.Check() {
+			violated = append(violated, id)
+		}
+	}
+	return violated
+}
@@ -0,0 +1,72 @@
+package propagator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifySolutions(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1, 2})
+	varB := AddVariableFromValues(csp, "B", []int{1, 2})
+
+	csp.AddConstraint(uncheckedDifferent{varA, varB})
+
+	model := csp.Model()
+
+	var violations []ConstraintInfo
+
+	solver := NewSolver(
+		WithSeed(0),
+		FindAllSolutions(),
+		VerifySolutionsWith(func(e Event, violated ConstraintInfo) {
+			violations = append(violations, violated)
+		}),
+	)
+
+	solver.Solve(model)
+
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %v", violations)
+	}
+	if violations[0].Type != "propagator.uncheckedDifferent" || violations[0].Domains[0] != "A" || violations[0].Domains[1] != "B" {
+		t.Fatalf("wrong violation: %v", violations[0])
+	}
+}
+
+func TestVerifySolutions_Panics(t *testing.T) {
+	csp := NewProblem()
+	varA := AddVariableFromValues(csp, "A", []int{1})
+	varB := AddVariableFromValues(csp, "B", []int{1})
+
+	csp.AddConstraint(uncheckedDifferent{varA, varB})
+
+	model := csp.Model()
+
+	solver := NewSolver(VerifySolutions())
+
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(r.(string), "propagator.uncheckedDifferent") {
+			t.Fatalf("expected panic naming the violated constraint, got %v", r)
+		}
+	}()
+
+	solver.Solve(model)
+}
+
+// uncheckedDifferent requires a and b to be different, but forgets to propagate it.
+type uncheckedDifferent struct {
+	a *Variable[int]
+	b *Variable[int]
+}
+
+func (u uncheckedDifferent) Scope() []DomainId {
+	return IdsOf(u.a, u.b)
+}
+
+func (u uncheckedDifferent) Propagate(m *Mutator) {}
+
+func (u uncheckedDifferent) Check() bool {
+	return u.a.GetAssignedValue() != u.b.GetAssignedValue()
+}
@@ -0,0 +1,116 @@
+package propagator
+
+import (
+	"math"
+)
+
+// minProbability is the probability below which an index is banned.
+const minProbability = 1e-10
+
+// weight holds the probability and priority of a single index in a domain.
+type weight struct {
+	// Product of probability modifiers
+	probability float64
+	// Sum of priority modifiers
+	priority int
+}
+
+// defaultWeight is the weight of an index without modifiers.
+var defaultWeight = weight{probability: 1.0, priority: 0}
+
+// isBannedProbability returns whether an index with the given probability is banned.
+func isBannedProbability(probability float64) bool {
+	return math.Abs(probability) < minProbability
+}
+
+// modifierKey identifies the modifier that a constraint set on an index of a domain.
+// Constraint -1 holds the base probability and base priority, which can only be modified by mutations made outside
+// of propagation. Only modifiers other than defaultWeight are stored.
+type modifierKey struct {
+	domain     DomainId
+	index      int
+	constraint constraintId
+}
+
+// indexChange records a change to a single index, so it can later be reverted.
+type indexChange struct {
+	index int
+	// banned is whether the index was banned by the change.
+	banned bool
+	// adjusted is whether the weight and modifier were changed. If set, old, hadModifier and oldModifier hold their
+	// values before the change.
+	adjusted    bool
+	old         weight
+	hadModifier bool
+	oldModifier weight
+}
+
+// adjust adjusts index i according to the given probability and priority for constraint. The new modifier replaces the
+// previous modifier of the constraint, but only its lower probability and higher priority are taken. A probability of
+// zero bans the index, and banned indices cannot be adjusted anymore. It returns the change and whether it was
+// possible at all to adjust this index.
+func (d *Domain) adjust(i int, constraint constraintId, probability float64, priority int) (indexChange, bool) {
+	if !d.isAvailable(i) {
+		return indexChange{}, false
+	}
+
+	if probability == 0.0 {
+		d.setIndex(i, d.weights()[i], false)
+		return indexChange{index: i, banned: true}, true
+	}
+
+	key := modifierKey{domain: d.id, index: i, constraint: constraint}
+	current, hasModifier := d.model.indexModifiers[key]
+	if !hasModifier {
+		current = defaultWeight
+	}
+
+	modifier := current
+	if probability < current.probability {
+		modifier.probability = probability
+	}
+	if priority > current.priority {
+		modifier.priority = priority
+	}
+	if modifier == current {
+		return indexChange{}, false
+	}
+
+	old := d.weights()[i]
+	adjusted := weight{
+		probability: old.probability / current.probability * modifier.probability,
+		priority:    old.priority - current.priority + modifier.priority,
+	}
+	if modifier == defaultWeight {
+		delete(d.model.indexModifiers, key)
+	} else {
+		d.model.indexModifiers[key] = modifier
+	}
+
+	banned := isBannedProbability(adjusted.probability)
+	d.setIndex(i, adjusted, !banned)
+
+	return indexChange{
+		index:       i,
+		banned:      banned,
+		adjusted:    true,
+		old:         old,
+		hadModifier: hasModifier,
+		oldModifier: current,
+	}, true
+}
+
+// revertChange reverts a change made by adjust for the given constraint.
+func (d *Domain) revertChange(constraint constraintId, change indexChange) {
+	w := d.weights()[change.index]
+	if change.adjusted {
+		w = change.old
+		key := modifierKey{domain: d.id, index: change.index, constraint: constraint}
+		if change.hadModifier {
+			d.model.indexModifiers[key] = change.oldModifier
+		} else {
+			delete(d.model.indexModifiers, key)
+		}
+	}
+	d.setIndex(change.index, w, true)
+}
@@ -0,0 +1,117 @@
+package propagator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDomain_IndexWeights(t *testing.T) {
+	csp := NewProblem()
+	variable := AddVariable(csp, "v", []DomainValue[int]{{0, 1.0, 1}, {2, 0.5, 2}, {0, 0.0, 3}})
+	csp.Model()
+
+	if variable.IndexProbability(0) != 1.0 || variable.IndexPriority(0) != 0 {
+		t.Fatalf("index should have default weight")
+	}
+	if variable.IndexProbability(1) != 0.5 || variable.IndexPriority(1) != 2 {
+		t.Fatalf("index should have base weight")
+	}
+	if variable.IndexProbability(2) != 0.0 || variable.IndexPriority(2) != math.MaxInt {
+		t.Fatalf("index should be banned")
+	}
+}
+
+func TestAdjustProbability(t *testing.T) {
+	csp := NewProblem()
+	variable := AddVariable(csp, "v", []DomainValue[int]{{0, 0.5, 1}, {0, 1.0, 2}})
+	csp.Model()
+
+	if _, success := variable.adjust(0, 3, 0.5, 0); !success {
+		t.Fatalf("index probability should be decremented")
+	}
+	if variable.IndexProbability(0) != 0.25 {
+		t.Fatalf("expected probability 0.25, got %f", variable.IndexProbability(0))
+	}
+
+	if _, success := variable.adjust(0, 3, 1.0, 0); success {
+		t.Fatalf("index probability should not be incremented")
+	}
+
+	if _, success := variable.adjust(0, 3, 0.25, 0); !success {
+		t.Fatalf("index probability should be decremented")
+	}
+	if variable.IndexProbability(0) != 0.125 {
+		t.Fatalf("modifier should be replaced, expected probability 0.125, got %f", variable.IndexProbability(0))
+	}
+
+	if _, success := variable.adjust(0, -1, 0.5, 0); success {
+		t.Fatalf("base probability should not be incremented")
+	}
+}
+
+func TestAdjustPriority(t *testing.T) {
+	csp := NewProblem()
+	variable := AddVariableFromValues(csp, "v", []int{1, 2})
+	csp.Model()
+
+	if _, success := variable.adjust(0, 3, 1.0, 1); !success {
+		t.Fatalf("index priority should be incremented")
+	}
+
+	if _, success := variable.adjust(0, 3, 1.0, 0); success {
+		t.Fatalf("index priority should not be decremented")
+	}
+
+	if _, success := variable.adjust(0, 3, 1.0, 3); !success {
+		t.Fatalf("index priority should be incremented")
+	}
+	if variable.IndexPriority(0) != 3 {
+		t.Fatalf("modifier should be replaced, expected priority 3, got %d", variable.IndexPriority(0))
+	}
+}
+
+func TestAdjustBanned(t *testing.T) {
+	csp := NewProblem()
+	variable := AddVariableFromValues(csp, "v", []int{1, 2})
+	csp.Model()
+
+	if _, success := variable.adjust(0, 3, 0.0, 0); !success {
+		t.Fatalf("index should be banned")
+	}
+	if _, success := variable.adjust(0, 4, 0.5, 1); success {
+		t.Fatalf("banned index should not be adjusted")
+	}
+}
+
+func TestMutation_Revert(t *testing.T) {
+	csp := NewProblem()
+	variable := AddVariableFromValues(csp, "v", []int{1, 2, 3})
+	model := csp.Model()
+
+	mutator := newMutator()
+	mutator.setActiveConstraint(model, 0, false)
+	mutator.Add(variable.Update(0.5, 1, 0, 1))
+	mutator.apply()
+	mutator.setActiveConstraint(model, 1, false)
+	mutator.Add(variable.Update(0.25, 2, 0), variable.Exclude(1))
+	mutator.apply()
+
+	if variable.IndexProbability(0) != 0.125 || variable.IndexPriority(0) != 3 || len(variable.AvailableIndices()) != 2 {
+		t.Fatalf("mutations should be applied")
+	}
+
+	mutator.revertPrevious()
+	if variable.IndexProbability(0) != 0.5 || variable.IndexPriority(0) != 1 || variable.IndexProbability(1) != 0.5 {
+		t.Fatalf("previous mutations should be reverted")
+	}
+
+	mutator.revertAll()
+	for i := 0; i < 3; i++ {
+		if variable.IndexProbability(i) != 1.0 || variable.IndexPriority(i) != 0 {
+			t.Fatalf("all mutations should be reverted")
+		}
+	}
+	if len(variable.model.indexModifiers) != 0 {
+		t.Fatalf("all modifiers should be reverted")
+	}
+}